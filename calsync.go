@@ -1,18 +1,25 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	ical "github.com/emersion/go-ical"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/slack-go/slack"
+	"github.com/teambition/rrule-go"
 )
 
 // ── Debug logger ─────────────────────────────────────────────────────────────
@@ -65,10 +72,28 @@ var windowsTZMap = map[string]string{
 	"E. South America Standard Time": "America/Sao_Paulo",
 }
 
+var (
+	tzCacheMu sync.Mutex
+	tzCache   = map[string]*time.Location{}
+)
+
+// resolveTimezone maps a TZID to a location. Results are cached so large feeds
+// don't repeat the lookup (and its log line) for every event.
 func resolveTimezone(tzid string) *time.Location {
 	if tzid == "" {
 		return time.Local
 	}
+	tzCacheMu.Lock()
+	defer tzCacheMu.Unlock()
+	if loc, ok := tzCache[tzid]; ok {
+		return loc
+	}
+	loc := lookupTimezone(tzid)
+	tzCache[tzid] = loc
+	return loc
+}
+
+func lookupTimezone(tzid string) *time.Location {
 	// IANA-Name direkt versuchen
 	if loc, err := time.LoadLocation(tzid); err == nil {
 		return loc
@@ -92,70 +117,367 @@ func startCalSyncTickCmd(interval time.Duration) tea.Cmd {
 	})
 }
 
-func pollCalendarCmd(cfg calSyncConfig) tea.Cmd {
+// icsReparseInterval bounds how long a cached parse is reused for an unchanged
+// feed, so events entering the window as time passes are still picked up.
+const icsReparseInterval = time.Hour
+
+// reusableFeedHash returns the hash of the cached feed, or "" if the cache is
+// too old to be reused and the feed must be parsed again.
+func (s calSyncState) reusableFeedHash(now time.Time) string {
+	if s.feedHash == "" || now.Sub(s.parsedAt) > icsReparseInterval {
+		return ""
+	}
+	return s.feedHash
+}
+
+func pollCalendarCmd(cfg calSyncConfig, prevHash string) tea.Cmd {
 	return func() tea.Msg {
 		now := time.Now()
 		logCal("Poll gestartet, now=%s", now.Format("15:04:05"))
-		events, err := fetchICSEvents(cfg.ICSUrl)
+		res, err := fetchICSEvents(cfg, prevHash, now)
 		if err != nil {
 			logCal("FEHLER beim Fetch: %v", err)
 			return calSyncErrMsg{Err: err, IsFatal: false}
 		}
-		logCal("Fetch OK: %d Events total", len(events))
-		active := filterActiveEvents(events, now)
+		if res.Unchanged {
+			logCal("Feed unverändert (sha256 %s) → Parsen übersprungen", res.Hash[:min(12, len(res.Hash))])
+			return calEventsMsg{FetchedAt: now, Hash: res.Hash, Unchanged: true}
+		}
+		logCal("Fetch OK: %d Events gelesen, %d im Fenster, %d übersprungen", res.Seen, len(res.Events), res.Skipped)
+		active := filterActiveEvents(res.Events, now)
 		logCal("Aktive Events (jetzt laufend): %d", len(active))
 		for _, ev := range active {
 			logCal("  → %q  start=%s end=%s", ev.Subject, ev.StartTime.Local().Format("15:04"), ev.EndTime.Local().Format("15:04"))
 		}
-		return calEventsMsg{Events: events, FetchedAt: now}
+		return calEventsMsg{Events: res.Events, FetchedAt: now, Hash: res.Hash}
 	}
 }
 
 // ── ICS Fetch + Parse ─────────────────────────────────────────────────────────
 
-func fetchICSEvents(icsURL string) ([]calEvent, error) {
+// icsFetchResult is the outcome of a single feed download.
+type icsFetchResult struct {
+	Events    []calEvent
+	Hash      string
+	Unchanged bool
+	Seen      int
+	Skipped   int
+}
+
+// fetchICSEvents downloads the feed into a temporary file while hashing it, so
+// that an unchanged feed (same sha256 as prevHash) is never parsed again. Changed
+// feeds are parsed event by event; only events overlapping the configured window
+// around now are kept, which keeps memory flat for feeds with years of history.
+func fetchICSEvents(cfg calSyncConfig, prevHash string, now time.Time) (icsFetchResult, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, icsURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.ICSUrl, nil)
 	if err != nil {
-		return nil, fmt.Errorf("ICS request: %w", err)
+		return icsFetchResult{}, fmt.Errorf("ICS request: %w", err)
 	}
 	req.Header.Set("User-Agent", "slack-status-cli/1.0")
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("ICS fetch: %w", err)
+		return icsFetchResult{}, fmt.Errorf("ICS fetch: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("ICS server: HTTP %d", resp.StatusCode)
+		return icsFetchResult{}, fmt.Errorf("ICS server: HTTP %d", resp.StatusCode)
 	}
 
-	cal, err := ical.NewDecoder(resp.Body).Decode()
+	spool, err := os.CreateTemp("", "slack-status-ics-*")
 	if err != nil {
-		return nil, fmt.Errorf("ICS parse: %w", err)
+		return icsFetchResult{}, fmt.Errorf("ICS spool: %w", err)
 	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
 
-	var events []calEvent
-	skipped := 0
-	for _, child := range cal.Children {
-		if child.Name != ical.CompEvent {
-			continue
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(spool, h), resp.Body); err != nil {
+		return icsFetchResult{}, fmt.Errorf("ICS fetch: %w", err)
+	}
+	hash := hex.EncodeToString(h.Sum(nil))
+	if prevHash != "" && hash == prevHash {
+		return icsFetchResult{Hash: hash, Unchanged: true}, nil
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return icsFetchResult{}, fmt.Errorf("ICS spool: %w", err)
+	}
+	from, to := cfg.eventWindow(now)
+	res, err := parseICSStream(spool, from, to)
+	if err != nil {
+		return icsFetchResult{}, err
+	}
+	res.Hash = hash
+	if res.Skipped > 0 {
+		logCal("WARNUNG: %d Events konnten nicht geparst werden", res.Skipped)
+	}
+	return res, nil
+}
+
+// icsEventProps are the VEVENT properties the parser looks at; everything
+// else (descriptions, attendees, alarms) is skipped without being copied.
+var icsEventProps = [][]byte{
+	[]byte(ical.PropUID), []byte(ical.PropSummary), []byte(ical.PropDateTimeStart), []byte(ical.PropDateTimeEnd),
+	[]byte(ical.PropRecurrenceRule), []byte(ical.PropRecurrenceDates), []byte(ical.PropExceptionDates), []byte(ical.PropRecurrenceID),
+}
+
+// icsEvent collects the wanted property lines of one VEVENT in a buffer that
+// is reused for the next event.
+type icsEvent struct {
+	buf   []byte
+	lines [][]byte
+}
+
+func (e *icsEvent) reset() {
+	e.buf, e.lines = e.buf[:0], e.lines[:0]
+}
+
+func (e *icsEvent) add(line []byte) {
+	start := len(e.buf)
+	e.buf = append(e.buf, line...)
+	e.lines = append(e.lines, e.buf[start:len(e.buf):len(e.buf)])
+}
+
+// line returns the first line of the property name, nil if there is none.
+func (e *icsEvent) line(name string) []byte {
+	for _, l := range e.lines {
+		if icsLineIs(l, []byte(name)) {
+			return l
+		}
+	}
+	return nil
+}
+
+// prop parses the first line of the property name, nil if there is none.
+func (e *icsEvent) prop(name string) *ical.Prop {
+	if l := e.line(name); l != nil {
+		return rawICSProp(l)
+	}
+	return nil
+}
+
+// component turns the collected lines into a VEVENT for parseICSEvent.
+func (e *icsEvent) component() *ical.Component {
+	comp := ical.NewComponent(ical.CompEvent)
+	for _, l := range e.lines {
+		comp.Props.Add(rawICSProp(l))
+	}
+	return comp
+}
+
+// icsLineIs reports whether line is the property name, case-insensitively.
+func icsLineIs(line, name []byte) bool {
+	return len(line) > len(name) && bytes.EqualFold(line[:len(name)], name) && (line[len(name)] == ':' || line[len(name)] == ';')
+}
+
+// rawICSProp splits a content line into name, parameters and the raw value.
+func rawICSProp(line []byte) *ical.Prop {
+	s := string(line)
+	head, value := s, ""
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' {
+			quoted = !quoted
+		} else if s[i] == ':' && !quoted {
+			head, value = s[:i], s[i+1:]
+			break
+		}
+	}
+	parts := strings.Split(head, ";")
+	prop := ical.NewProp(strings.ToUpper(parts[0]))
+	prop.Value = value
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		prop.Params.Set(strings.ToUpper(k), strings.Trim(v, `"`))
+	}
+	return prop
+}
+
+// parseICSStream reads an ICS feed line by line. Of each VEVENT only the
+// properties above are kept; its DTSTART/DTEND are checked first, and only
+// events overlapping [from, to] are built. Recurring events are expanded
+// into their occurrences inside the window; occurrences moved by a
+// RECURRENCE-ID override are replaced by the override.
+func parseICSStream(r io.Reader, from, to time.Time) (icsFetchResult, error) {
+	var res icsFetchResult
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var ev icsEvent
+	overridden := map[string]bool{}
+	var generated []bool // parallel to res.Events: occurrence of a series
+	depth := 0
+	endEvent := func() {
+		res.Seen++
+		if ev.line(ical.PropRecurrenceID) != nil {
+			if id, uid := ev.prop(ical.PropRecurrenceID), ev.prop(ical.PropUID); uid != nil {
+				if t, err := parsePropDateTime(id); err == nil {
+					overridden[occurrenceID(uid.Value, t)] = true
+				}
+			}
 		}
-		ev, err := parseICSEvent(child)
+		evs, recurring, err := windowEvents(&ev, from, to)
 		if err != nil {
 			logCal("Event übersprungen: %v", err)
-			skipped++
+			res.Skipped++
+			return
+		}
+		for _, e := range evs {
+			res.Events = append(res.Events, e)
+			generated = append(generated, recurring)
+		}
+	}
+	var line []byte
+	process := func() {
+		switch {
+		case depth == 0 && bytes.EqualFold(line, []byte("BEGIN:VEVENT")):
+			ev.reset()
+			depth = 1
+		case depth == 0:
+		case len(line) > 6 && bytes.EqualFold(line[:6], []byte("BEGIN:")):
+			depth++
+		case len(line) > 4 && bytes.EqualFold(line[:4], []byte("END:")):
+			if depth--; depth == 0 {
+				endEvent()
+			}
+		case depth == 1:
+			for _, name := range icsEventProps {
+				if icsLineIs(line, name) {
+					ev.add(line)
+					break
+				}
+			}
+		}
+	}
+	// Folded lines (continuations start with a space or tab) are joined
+	// before they are looked at.
+	for sc.Scan() {
+		l := bytes.TrimRight(sc.Bytes(), "\r")
+		if len(l) > 0 && (l[0] == ' ' || l[0] == '\t') {
+			line = append(line, l[1:]...)
 			continue
 		}
-		events = append(events, ev)
+		process()
+		line = append(line[:0], l...)
+	}
+	if err := sc.Err(); err != nil {
+		return icsFetchResult{}, fmt.Errorf("ICS read: %w", err)
 	}
-	if skipped > 0 {
-		logCal("WARNUNG: %d Events konnten nicht geparst werden", skipped)
+	process()
+	if depth > 0 {
+		return icsFetchResult{}, errors.New("ICS parse: unvollständiges VEVENT am Dateiende")
+	}
+	if len(overridden) > 0 {
+		kept := res.Events[:0]
+		for i, e := range res.Events {
+			if !generated[i] || !overridden[e.ID] {
+				kept = append(kept, e)
+			}
+		}
+		res.Events = kept
 	}
-	return events, nil
+	return res, nil
+}
+
+// occurrenceID identifies one occurrence of a recurring event.
+func occurrenceID(uid string, start time.Time) string {
+	return uid + "/" + start.UTC().Format("20060102T150405Z")
+}
+
+// windowEvents returns the events of one VEVENT that overlap [from, to]:
+// the event itself, or the occurrences of a recurring one.
+func windowEvents(ev *icsEvent, from, to time.Time) ([]calEvent, bool, error) {
+	in := func(e calEvent) bool { return e.EndTime.After(from) && !e.StartTime.After(to) }
+	startProp, endProp := ev.prop(ical.PropDateTimeStart), ev.prop(ical.PropDateTimeEnd)
+	if startProp == nil || endProp == nil {
+		_, err := parseICSEvent(ev.component())
+		return nil, false, err
+	}
+	start, err := parsePropDateTime(startProp)
+	if err != nil {
+		return nil, false, fmt.Errorf("DTSTART %q: %w", startProp.Value, err)
+	}
+	end, err := parsePropDateTime(endProp)
+	if err != nil {
+		return nil, false, fmt.Errorf("DTEND %q: %w", endProp.Value, err)
+	}
+	if ev.line(ical.PropRecurrenceRule) == nil && ev.line(ical.PropRecurrenceDates) == nil {
+		if !in(calEvent{StartTime: start, EndTime: end}) {
+			return nil, false, nil
+		}
+		e, err := parseICSEvent(ev.component())
+		if err != nil {
+			return nil, false, err
+		}
+		if id := ev.prop(ical.PropRecurrenceID); id != nil {
+			if t, err := parsePropDateTime(id); err == nil {
+				e.ID = occurrenceID(e.ID, t)
+			}
+		}
+		return []calEvent{e}, false, nil
+	}
+
+	comp := ev.component()
+	master, err := parseICSEvent(comp)
+	if err != nil {
+		return nil, true, err
+	}
+	set, err := recurrenceSet(comp, start, comp.Props.Get(ical.PropRecurrenceRule))
+	if err != nil {
+		return nil, true, err
+	}
+	length := end.Sub(start)
+	var out []calEvent
+	for _, at := range set.Between(from.Add(-length), to, true) {
+		e := master
+		e.ID = occurrenceID(master.ID, at)
+		e.StartTime, e.EndTime = at.UTC(), at.Add(length).UTC()
+		if in(e) {
+			out = append(out, e)
+		}
+	}
+	return out, true, nil
+}
+
+// recurrenceSet builds the occurrences of a recurring event from RRULE,
+// RDATE and EXDATE, in the time zone of its DTSTART so weekly meetings keep
+// their wall-clock time across DST changes.
+func recurrenceSet(comp *ical.Component, start time.Time, rule *ical.Prop) (*rrule.Set, error) {
+	set := &rrule.Set{}
+	set.DTStart(start)
+	if rule != nil {
+		opt, err := rrule.StrToROptionInLocation(rule.Value, start.Location())
+		if err != nil {
+			return nil, fmt.Errorf("RRULE %q: %w", rule.Value, err)
+		}
+		opt.Dtstart = start
+		r, err := rrule.NewRRule(*opt)
+		if err != nil {
+			return nil, fmt.Errorf("RRULE %q: %w", rule.Value, err)
+		}
+		set.RRule(r)
+	}
+	dates := func(name string, add func(time.Time)) {
+		for _, p := range comp.Props.Values(name) {
+			for _, v := range strings.Split(p.Value, ",") {
+				one := p
+				one.Value = v
+				if t, err := parsePropDateTime(&one); err == nil {
+					add(t)
+				} else {
+					logCal("%s %q ignoriert: %v", name, v, err)
+				}
+			}
+		}
+	}
+	dates(ical.PropRecurrenceDates, set.RDate)
+	dates(ical.PropExceptionDates, set.ExDate)
+	return set, nil
 }
 
 func parseICSEvent(comp *ical.Component) (calEvent, error) {
@@ -190,14 +512,6 @@ func parseICSEvent(comp *ical.Component) (calEvent, error) {
 	// All-day: DTSTART hat VALUE=DATE (nur Datum, keine Uhrzeit)
 	isAllDay := startProp.Params.Get(ical.ParamValue) == "DATE" || len(strings.ReplaceAll(startProp.Value, "-", "")) == 8
 
-	logCal("Event: %q  start=%s end=%s allday=%v id=%s",
-		subject,
-		startTime.Local().Format("2006-01-02 15:04"),
-		endTime.Local().Format("2006-01-02 15:04"),
-		isAllDay,
-		id[:min(8, len(id))],
-	)
-
 	return calEvent{
 		ID:        id,
 		Subject:   subject,
@@ -214,6 +528,14 @@ func parsePropDateTime(prop *ical.Prop) (time.Time, error) {
 	tzid := prop.Params.Get(ical.ParamTimezoneID)
 	loc := resolveTimezone(tzid)
 
+	// go-ical lädt die TZID bei jedem Aufruf selbst (ungecacht, ohne
+	// Windows-Namen) → lokale Zeit mit TZID direkt in loc parsen
+	if tzid != "" {
+		if t, err := time.ParseInLocation("20060102T150405", prop.Value, loc); err == nil {
+			return t, nil
+		}
+	}
+
 	// Versuche go-ical-Parsing mit aufgelöstem Location
 	t, err := prop.DateTime(loc)
 	if err == nil {
@@ -279,58 +601,178 @@ func earliestStartEvent(events []calEvent) calEvent {
 	return earliest
 }
 
-// ── Slack-Status Cmds ─────────────────────────────────────────────────────────
-
-func saveCurrentStatusCmd(client *slack.Client, statePath string) tea.Cmd {
-	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		profile, err := client.GetUserProfileContext(ctx, &slack.GetUserProfileParameters{})
-		if err != nil {
-			return calSyncErrMsg{Err: fmt.Errorf("status sichern: %w", err), IsFatal: false}
-		}
+// ── Meeting-linked expiries ───────────────────────────────────────────────────
+
+// parseUntilMeeting recognises the calendar keywords accepted for untilMeeting
+// and the until field of the manual form.
+func parseUntilMeeting(v string) (string, bool) {
+	key := strings.ToLower(strings.Join(strings.Fields(v), ""))
+	switch key {
+	case "nextmeeting", "next-meeting":
+		return untilNextMeeting, true
+	case "meetingend", "meeting-end", "endofmeeting":
+		return untilMeetingEnd, true
+	}
+	return "", false
+}
 
-		snap := savedStatus{
-			Text:           profile.StatusText,
-			Emoji:          profile.StatusEmoji,
-			ExpirationUnix: int64(profile.StatusExpiration),
-			SavedAt:        time.Now().Unix(),
+// meetingExpiry resolves an untilMeeting value against the events of the last
+// calendar poll: the start of the next meeting or the end of the running one.
+func meetingExpiry(kind string, enabled bool, events []calEvent, now time.Time) (time.Time, error) {
+	if !enabled {
+		return time.Time{}, errors.New("calendar sync is disabled; meeting-based expiry needs calendar-sync.json")
+	}
+	switch kind {
+	case untilNextMeeting:
+		var next *calEvent
+		for i, ev := range events {
+			if ev.IsAllDay || !ev.StartTime.After(now) {
+				continue
+			}
+			if next == nil || ev.StartTime.Before(next.StartTime) {
+				next = &events[i]
+			}
 		}
-		logCal("Aktuellen Status gesichert: text=%q emoji=%q exp=%d", snap.Text, snap.Emoji, snap.ExpirationUnix)
-
-		data, err := json.MarshalIndent(snap, "", "  ")
-		if err != nil {
-			return calSyncErrMsg{Err: fmt.Errorf("status sichern: marshal: %w", err), IsFatal: false}
+		if next == nil {
+			return time.Time{}, errors.New("no upcoming meeting in calendar")
 		}
-		if err := os.WriteFile(statePath, data, 0o644); err != nil {
-			return calSyncErrMsg{Err: fmt.Errorf("status sichern: schreiben: %w", err), IsFatal: false}
+		return next.StartTime, nil
+	case untilMeetingEnd:
+		active := filterActiveEvents(events, now)
+		if len(active) == 0 {
+			return time.Time{}, errors.New("no meeting in progress")
 		}
-		return calStatusSavedMsg{Snapshot: snap}
+		return earliestStartEvent(active).EndTime, nil
 	}
+	return time.Time{}, fmt.Errorf("unknown untilMeeting %q (use %s or %s)", kind, untilNextMeeting, untilMeetingEnd)
 }
 
-func restorePreviousStatusCmd(client *slack.Client, statePath string) tea.Cmd {
+// ── Slack-Status Cmds ─────────────────────────────────────────────────────────
+
+// saveCurrentStatusCmd snapshots status, presence and, when cal-sync snoozes
+// during meetings, the DND state of each target to restore afterwards. The
+// first target's snapshot goes to statePath, the others' next to it.
+func saveCurrentStatusCmd(targets []workspace, statePath string, withDND bool) tea.Cmd {
 	return func() tea.Msg {
-		snap, err := loadSavedStatus(statePath)
-		if err != nil {
-			return calSyncErrMsg{Err: fmt.Errorf("status wiederherstellen: %w", err), IsFatal: false}
+		var mu sync.Mutex
+		snaps := map[string]savedStatus{}
+		results := eachWorkspace(targets, func(ctx context.Context, ws workspace) error {
+			profile, err := ws.client.GetUserProfileContext(ctx, &slack.GetUserProfileParameters{})
+			if err != nil {
+				return fmt.Errorf("status sichern: %w", err)
+			}
+
+			snap := savedStatus{
+				Text:           profile.StatusText,
+				Emoji:          profile.StatusEmoji,
+				ExpirationUnix: int64(profile.StatusExpiration),
+				SavedAt:        time.Now().Unix(),
+			}
+			if p, manual, err := currentPresence(ctx, ws.client); err == nil {
+				snap.Presence = restorablePresence(p, manual)
+			} else {
+				logCal("%sPresence nicht gelesen: %v", wsPrefix(ws), err)
+			}
+			if withDND {
+				snap.DNDManaged = true
+				if st, err := currentDND(ctx, ws.client); err == nil && st.Snoozing {
+					snap.DNDSnoozeEnd = st.SnoozeEnd.Unix()
+				} else if err != nil {
+					logCal("%sDND nicht gelesen: %v", wsPrefix(ws), err)
+				}
+			}
+			logCal("%sAktuellen Status gesichert: text=%q emoji=%q exp=%d", wsPrefix(ws), snap.Text, snap.Emoji, snap.ExpirationUnix)
+
+			data, err := json.MarshalIndent(snap, "", "  ")
+			if err != nil {
+				return fmt.Errorf("status sichern: marshal: %w", err)
+			}
+			if err := os.WriteFile(ws.statePath(statePath, ws.name == targets[0].name), data, 0o644); err != nil {
+				return fmt.Errorf("status sichern: schreiben: %w", err)
+			}
+			mu.Lock()
+			snaps[ws.name] = snap
+			mu.Unlock()
+			return nil
+		})
+		// The meeting status is set as long as one workspace could be saved;
+		// the others keep their status.
+		for _, r := range results {
+			if r.Err == nil {
+				return calStatusSavedMsg{Snapshot: snaps[r.Workspace]}
+			}
 		}
-
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		if err := client.SetUserCustomStatusContext(ctx, snap.Text, snap.Emoji, snap.ExpirationUnix); err != nil {
-			return calSyncErrMsg{Err: fmt.Errorf("status wiederherstellen: %w", err), IsFatal: false}
+		if len(targets) == 0 {
+			return calSyncErrMsg{Err: errors.New("status sichern: kein Workspace mit Cal-Sync"), IsFatal: true}
 		}
+		return calSyncErrMsg{Err: joinResults(targets, results), IsFatal: false}
+	}
+}
 
-		_ = os.Remove(statePath)
-		logCal("Vorherigen Status wiederhergestellt: text=%q emoji=%q", snap.Text, snap.Emoji)
-		return calStatusRestoredMsg{PreviousText: snap.Text}
+// restorePreviousStatusCmd sets the saved statuses again; workspaces in
+// keep, whose status was changed elsewhere during the meeting, only drop
+// their snapshot.
+func restorePreviousStatusCmd(targets []workspace, statePath string, keep map[string]bool) tea.Cmd {
+	return func() tea.Msg {
+		var mu sync.Mutex
+		var previous string
+		results := eachWorkspace(targets, func(ctx context.Context, ws workspace) error {
+			path := ws.statePath(statePath, ws.name == targets[0].name)
+			snap, err := loadSavedStatus(path)
+			if errors.Is(err, os.ErrNotExist) {
+				// Saving failed here, so this workspace never got the meeting status.
+				logCal("%sKein gesicherter Status", wsPrefix(ws))
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("status wiederherstellen: %w", err)
+			}
+			if keep[ws.name] {
+				_ = os.Remove(path)
+				logCal("%sStatus wurde während des Meetings geändert → nicht wiederhergestellt", wsPrefix(ws))
+				return nil
+			}
+			// A queued restore is replayed once Slack is reachable again, so
+			// the snapshot is done with either way.
+			if err := ws.setStatus(ctx, snap.Text, snap.Emoji, snap.ExpirationUnix); errors.Is(err, errQueued) {
+				logCal("%sStatus-Wiederherstellung in Offline-Queue: %v", wsPrefix(ws), err)
+			} else if err != nil {
+				return fmt.Errorf("status wiederherstellen: %w", err)
+			}
+			if snap.Presence != "" {
+				if err := ws.client.SetUserPresenceContext(ctx, snap.Presence); err != nil {
+					logCal("%sPresence nicht wiederhergestellt: %v", wsPrefix(ws), err)
+				}
+			}
+			if snap.DNDManaged {
+				// Resume a snooze that outlasts the meeting, otherwise end ours.
+				until := time.Time{}
+				if end := time.Unix(snap.DNDSnoozeEnd, 0); snap.DNDSnoozeEnd > 0 && end.After(time.Now()) {
+					until = end
+				}
+				if _, err := snooze(ctx, ws.client, until); err != nil {
+					logCal("%sDND nicht wiederhergestellt: %v", wsPrefix(ws), err)
+				}
+			}
+
+			_ = os.Remove(path)
+			logCal("%sVorherigen Status wiederhergestellt: text=%q emoji=%q", wsPrefix(ws), snap.Text, snap.Emoji)
+			mu.Lock()
+			if previous == "" || ws.name == targets[0].name {
+				previous = snap.Text
+			}
+			mu.Unlock()
+			return nil
+		})
+		err := joinResults(targets, results)
+		if err != nil && !anySucceeded(results) {
+			return calSyncErrMsg{Err: err, IsFatal: false}
+		}
+		return calStatusRestoredMsg{PreviousText: previous, Err: err}
 	}
 }
 
-func setMeetingStatusCmd(client *slack.Client, cfg calSyncConfig, event calEvent) tea.Cmd {
+func setMeetingStatusCmd(targets []workspace, cfg calSyncConfig, event calEvent) tea.Cmd {
 	return func() tea.Msg {
 		text := cfg.DefaultText
 		if cfg.UseEventTitle && event.Subject != "" {
@@ -341,17 +783,35 @@ func setMeetingStatusCmd(client *slack.Client, cfg calSyncConfig, event calEvent
 
 		logCal("Setze Meeting-Status: text=%q emoji=%q bis=%s", text, emoji, event.EndTime.Local().Format("15:04"))
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		if err := client.SetUserCustomStatusContext(ctx, text, emoji, expiration); err != nil {
-			return calSyncErrMsg{Err: fmt.Errorf("meeting-status setzen: %w", err), IsFatal: false}
+		results := eachWorkspace(targets, func(ctx context.Context, ws workspace) error {
+			if err := ws.setStatus(ctx, text, emoji, expiration); err != nil {
+				return fmt.Errorf("meeting-status setzen: %w", err)
+			}
+			if cfg.SnoozeDuringMeetings {
+				if _, err := snooze(ctx, ws.client, event.EndTime); err != nil {
+					logCal("%sDND setzen fehlgeschlagen: %v", wsPrefix(ws), err)
+				}
+			}
+			return nil
+		})
+		err := joinResults(targets, results)
+		if err != nil && !anySucceeded(results) {
+			return calSyncErrMsg{Err: err, IsFatal: false}
 		}
 		return calStatusSetMsg{
 			EventID:     event.ID,
 			EventEnd:    event.EndTime,
 			StatusText:  text,
 			StatusEmoji: emoji,
+			Err:         err,
 		}
 	}
 }
+
+// wsPrefix labels debug log lines of named workspaces.
+func wsPrefix(ws workspace) string {
+	if ws.name == "" {
+		return ""
+	}
+	return "[" + ws.name + "] "
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// syntheticICS builds a feed of n events, one per day ending at end, every
+// tenth a weekly recurring one with a long description, as exported by
+// calendars with years of history.
+func syntheticICS(n int, end time.Time) []byte {
+	var b bytes.Buffer
+	b.WriteString("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//test//EN\r\n")
+	b.WriteString("BEGIN:VTIMEZONE\r\nTZID:W. Europe Standard Time\r\nBEGIN:STANDARD\r\nDTSTART:16010101T030000\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\nEND:STANDARD\r\nEND:VTIMEZONE\r\n")
+	for i := 0; i < n; i++ {
+		start := end.AddDate(0, 0, i-n+1).Truncate(time.Hour)
+		fmt.Fprintf(&b, "BEGIN:VEVENT\r\nUID:event-%d@test\r\nSUMMARY:Meeting %d\r\n", i, i)
+		fmt.Fprintf(&b, "DTSTART;TZID=W. Europe Standard Time:%s\r\n", start.Format("20060102T150405"))
+		fmt.Fprintf(&b, "DTEND;TZID=W. Europe Standard Time:%s\r\n", start.Add(30*time.Minute).Format("20060102T150405"))
+		if i%10 == 0 {
+			b.WriteString("RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=52\r\n")
+			b.WriteString("DESCRIPTION:" + string(bytes.Repeat([]byte("Agenda, notes and dial-in details. "), 20)) + "\r\n")
+			b.WriteString("BEGIN:VALARM\r\nACTION:DISPLAY\r\nTRIGGER:-PT15M\r\nEND:VALARM\r\n")
+		}
+		b.WriteString("END:VEVENT\r\n")
+	}
+	b.WriteString("END:VCALENDAR\r\n")
+	return b.Bytes()
+}
+
+func TestParseICSStreamWindow(t *testing.T) {
+	now := time.Now()
+	feed := syntheticICS(400, now.AddDate(0, 0, 30))
+	from, to := now.AddDate(0, 0, -7), now.AddDate(0, 0, 14)
+	res, err := parseICSStream(bytes.NewReader(feed), from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Seen != 400 || res.Skipped != 0 {
+		t.Fatalf("seen %d, skipped %d; want 400, 0", res.Seen, res.Skipped)
+	}
+	single := 0
+	for _, ev := range res.Events {
+		if !ev.EndTime.After(from) || ev.StartTime.After(to) {
+			t.Errorf("%s (%s) is outside the window", ev.Subject, ev.StartTime)
+		}
+		if !strings.Contains(ev.ID, "/") {
+			single++
+		}
+	}
+	if single < 18 || single > 21 {
+		t.Fatalf("kept %d single events, want the ~19 non-recurring days of the window", single)
+	}
+	if len(res.Events) == single {
+		t.Fatal("no occurrences of the weekly series reaching into the window")
+	}
+}
+
+func TestParseICSStreamRecurring(t *testing.T) {
+	// A weekly meeting since 2024, one date cancelled, one moved, and the
+	// window crossing the switch to summer time on 29 March 2026.
+	feed := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:weekly@test",
+		"SUMMARY:Jour fixe",
+		"DTSTART;TZID=W. Europe Standard Time:20240101T090000",
+		"DTEND;TZID=W. Europe Standard Time:20240101T093000",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO",
+		"EXDATE;TZID=W. Europe Standard Time:20260323T090000",
+		"DESCRIPTION:long",
+		"  folded",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:weekly@test",
+		"RECURRENCE-ID;TZID=W. Europe Standard Time:20260330T090000",
+		"SUMMARY:Jour fixe (verschoben)",
+		"DTSTART;TZID=W. Europe Standard Time:20260330T110000",
+		"DTEND;TZID=W. Europe Standard Time:20260330T113000",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	from := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC)
+	res, err := parseICSStream(strings.NewReader(feed), from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, ev := range res.Events {
+		got = append(got, ev.Subject+" "+ev.StartTime.Format(time.RFC3339))
+	}
+	want := []string{
+		"Jour fixe 2026-04-06T07:00:00Z",
+		"Jour fixe (verschoben) 2026-03-30T09:00:00Z",
+	}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestFetchICSEventsSkipsUnchangedFeed(t *testing.T) {
+	feed := syntheticICS(50, time.Now())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(feed)
+	}))
+	defer srv.Close()
+	cfg := calSyncConfig{ICSUrl: srv.URL, WindowPastDays: 7, WindowFutureDays: 7}
+
+	first, err := fetchICSEvents(cfg, "", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Unchanged || first.Hash == "" || first.Seen != 50 {
+		t.Fatalf("first fetch: unchanged %v, hash %q, seen %d", first.Unchanged, first.Hash, first.Seen)
+	}
+	second, err := fetchICSEvents(cfg, first.Hash, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Unchanged || second.Hash != first.Hash || second.Seen != 0 || second.Events != nil {
+		t.Fatalf("second fetch was parsed again: %+v", second)
+	}
+
+	feed = append(feed[:len(feed):len(feed)], "X-CHANGED:1\r\n"...)
+	third, err := fetchICSEvents(cfg, first.Hash, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Unchanged || third.Hash == first.Hash {
+		t.Fatal("changed feed was not parsed")
+	}
+}
+
+// BenchmarkParseICSStream parses a multi-MB feed of which only a few weeks
+// fall into the window.
+func BenchmarkParseICSStream(b *testing.B) {
+	now := time.Now()
+	feed := syntheticICS(20000, now.AddDate(0, 0, 60))
+	from, to := now.AddDate(0, 0, -7), now.AddDate(0, 0, 30)
+	b.SetBytes(int64(len(feed)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := parseICSStream(bytes.NewReader(feed), from, to); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
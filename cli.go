@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const cliUsage = `Usage:
+  slack-status                      start the TUI
+  slack-status daemon               run schedule, queue and follow-ups without UI
+  slack-status later add --at WHEN [--template LABEL | --text TEXT --emoji EMOJI] [--until UNTIL] [--late apply|skip]
+  slack-status later list
+  slack-status later cancel ID
+  slack-status login [--workspace NAME]  log in with Slack in the browser
+  slack-status token-store keyring|file|plain  move the tokens to another store
+`
+
+// runCLI handles the non-interactive subcommands and returns the exit code.
+func runCLI(args []string, stdout, stderr io.Writer) int {
+	var err error
+	switch args[0] {
+	case "daemon":
+		err = runDaemon(stdout)
+	case "later":
+		err = runLater(args[1:], stdout)
+	case "login":
+		err = runLogin(args[1:], stdout)
+	case "token-store":
+		err = runTokenStore(args[1:], stdout)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, cliUsage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n%s", args[0], cliUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
+
+func cliConfig() (config, string, error) {
+	cfgPath, err := resolvePath(configName)
+	if err != nil {
+		return config{}, defaultConfigPath(), err
+	}
+	cfg, err := loadConfig(cfgPath)
+	if err != nil {
+		return config{}, cfgPath, err
+	}
+	if err := configureHolidays(cfg); err != nil {
+		return cfg, cfgPath, err
+	}
+	return cfg, cfgPath, configureWorkingHours(cfg.WorkingHours)
+}
+
+func runLater(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("later: expected add, list or cancel")
+	}
+	cfg, cfgPath, err := cliConfig()
+	if err != nil {
+		return err
+	}
+	path := queuePathFor(cfgPath)
+	now := time.Now()
+
+	switch args[0] {
+	case "add":
+		fs := flag.NewFlagSet("later add", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		at := fs.String("at", "", "when to apply the status")
+		ref := fs.String("template", "", "template label or id")
+		text := fs.String("text", "", "status text")
+		emoji := fs.String("emoji", "", "status emoji")
+		until := fs.String("until", "", "expiry, relative to the start")
+		late := fs.String("late", lateApply, "apply or skip if the time was missed")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		var templates []template
+		if *ref != "" {
+			tmplPath, err := resolvePath(templatesName)
+			if err != nil {
+				return err
+			}
+			if templates, err = readTemplates(tmplPath); err != nil {
+				return err
+			}
+		}
+		q, err := newQueuedStatus(*at, *ref, *text, *emoji, *until, *late, now, effectiveRollOver(cfg), templates)
+		if err != nil {
+			return err
+		}
+		if _, err := updateQueue(path, putQueued(q)); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%s  %s\n", q.ID, q.describe(now))
+	case "list":
+		items, err := loadQueue(path)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			fmt.Fprintln(stdout, "nothing queued")
+		}
+		for _, q := range items {
+			fmt.Fprintf(stdout, "%s  %s\n", q.ID, q.describe(now))
+		}
+	case "cancel":
+		if len(args) != 2 {
+			return errors.New("later cancel: expected an id")
+		}
+		before, err := loadQueue(path)
+		if err != nil {
+			return err
+		}
+		after, err := updateQueue(path, removeQueued(args[1]))
+		if err != nil {
+			return err
+		}
+		if len(after) == len(before) {
+			return fmt.Errorf("no queued status with id %s", args[1])
+		}
+		fmt.Fprintln(stdout, "cancelled", args[1])
+	default:
+		return fmt.Errorf("later: unknown command %q", args[0])
+	}
+	return nil
+}
+
+// runLogin does the browser login without the TUI and stores the token for
+// the primary or the named workspace.
+func runLogin(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	ws := fs.String("workspace", "", "workspace to store the token for")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfgPath, err := resolvePath(configName)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(cfgPath)
+	if err != nil && !errors.Is(err, errNoToken) {
+		return err
+	}
+	oc, err := oauthFor(cfg)
+	if err != nil {
+		return err
+	}
+	s, err := startLogin(oc)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Opening the browser. If nothing happens, visit:\n%s\n", s.URL)
+	_ = openBrowser(s.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), loginTimeout)
+	defer cancel()
+	cred, team, err := s.wait(ctx)
+	if err != nil {
+		return err
+	}
+	cred.Workspace = *ws
+	switch msg := saveConfigCmd(cfgPath, cfg, cred, effectiveConfirmDelete(cfg))().(type) {
+	case errMsg:
+		return msg.err
+	case configUpdatedMsg:
+		fmt.Fprintf(stdout, "Logged in to %s, token saved to %s\n", missing(team, "Slack"), msg.path)
+	}
+	return nil
+}
+
+// runTokenStore moves the tokens of config.json into another store and
+// removes them from the old one.
+func runTokenStore(args []string, stdout io.Writer) error {
+	if len(args) != 1 {
+		return errors.New("token-store: expected keyring, file or plain")
+	}
+	target := args[0]
+	if target != tokenStorePlain {
+		if _, err := secretStoreFor(target, configName); err != nil {
+			return err
+		}
+	}
+	cfgPath, err := resolvePath(configName)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(cfgPath)
+	if err != nil {
+		return err
+	}
+	previous := cfg.TokenStore
+	if previous == target {
+		fmt.Fprintln(stdout, "tokens are already in", target)
+		return nil
+	}
+	cfg.TokenStore = target
+	if err := writeConfig(cfgPath, cfg); err != nil {
+		return err
+	}
+	if previous != "" && previous != tokenStorePlain {
+		if old, err := secretStoreFor(previous, cfgPath); err == nil {
+			if err := old.clear(); err != nil {
+				fmt.Fprintf(stdout, "could not remove the tokens from %s: %v\n", previous, err)
+			}
+		}
+	}
+	fmt.Fprintf(stdout, "tokens moved to %s\n", tokenStoreLabel(cfg))
+	return nil
+}
+
+// headlessModel runs the regular model without a renderer and logs what the
+// TUI would show in its message line.
+type headlessModel struct {
+	model
+	out io.Writer
+}
+
+func (h headlessModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	before, beforeErr, beforeChange := h.message, h.err, h.lastChange
+	next, cmd := h.model.Update(msg)
+	h.model = next.(model)
+	if h.lastChange != nil && h.lastChange != beforeChange {
+		fmt.Fprintf(h.out, "%s %s\n", time.Now().Format("2006-01-02 15:04:05"), h.lastChange.describe(len(h.workspaces) > 1))
+	}
+	if h.message != before && h.message != "" {
+		fmt.Fprintf(h.out, "%s %s\n", time.Now().Format("2006-01-02 15:04:05"), h.message)
+	}
+	if h.err != nil && h.err != beforeErr {
+		fmt.Fprintf(h.out, "%s error: %v\n", time.Now().Format("2006-01-02 15:04:05"), h.err)
+	}
+	return h, cmd
+}
+
+// runDaemon applies the schedule, queued statuses, follow-ups and cal-sync
+// until interrupted. Run it instead of the TUI, not alongside it.
+func runDaemon(stdout io.Writer) error {
+	m := initialModel()
+	if m.err != nil {
+		return m.err
+	}
+	if m.client == nil {
+		return errors.New("no Slack token configured")
+	}
+	fmt.Fprintln(stdout, "slack-status daemon running, Ctrl+C to stop")
+	p := tea.NewProgram(headlessModel{model: m, out: stdout}, tea.WithoutRenderer(), tea.WithInput(nil))
+	teaProgram = p
+	_, err := p.Run()
+	return err
+}
@@ -2,27 +2,30 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/slack-go/slack"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func fetchStatusCmd(client *slack.Client) tea.Cmd {
+func fetchStatusCmd(ws workspace) tea.Cmd {
 	return func() tea.Msg {
-		if client == nil {
+		if ws.client == nil {
 			return errMsg{errors.New("no Slack client configured")}
 		}
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		profile, err := client.GetUserProfileContext(ctx, &slack.GetUserProfileParameters{})
+		profile, err := ws.client.GetUserProfileContext(ctx, &slack.GetUserProfileParameters{})
 		if err != nil {
-			return errMsg{err}
+			return errMsg{ws.wrap(err)}
 		}
 
 		user := profile.DisplayName
@@ -37,64 +40,74 @@ func fetchStatusCmd(client *slack.Client) tea.Cmd {
 		}
 
 		info := statusInfo{
-			User:       user,
-			Text:       profile.StatusText,
-			Emoji:      profile.StatusEmoji,
-			Expiration: exp,
+			Workspace:      ws.name,
+			User:           user,
+			Text:           profile.StatusText,
+			Emoji:          profile.StatusEmoji,
+			Expiration:     exp,
+			ExpirationUnix: int64(profile.StatusExpiration),
 		}
 		return statusMsg(info)
 	}
 }
 
-func setStatusCmd(client *slack.Client, text, emoji string, duration *int, until string) tea.Cmd {
+// setStatusCmd sets a status with an already resolved expiry (zero = none)
+// in all targets and reports the result per workspace.
+func setStatusCmd(targets []workspace, text, emoji string, expiration time.Time, vars statusVars) tea.Cmd {
 	return func() tea.Msg {
-		if client == nil {
-			return errMsg{errors.New("no Slack client configured")}
-		}
-		expiration := int64(0)
-
-		if duration != nil {
-			expiration = time.Now().Add(time.Duration(*duration) * time.Minute).Unix()
-		}
-		if until != "" {
-			t, err := time.Parse("15:04", until)
-			if err != nil {
-				return errMsg{fmt.Errorf("until time must be HH:MM")}
-			}
-			now := time.Now()
-			target := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
-			expiration = target.Unix()
-		}
-
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		if err := client.SetUserCustomStatusContext(ctx, text, emoji, expiration); err != nil {
+		vars.now = time.Now()
+		vars.expiration = expiration
+		rendered, err := renderStatusText(text, vars)
+		if err != nil {
 			return errMsg{err}
 		}
-		return setStatusMsg("Status updated")
+		exp := int64(0)
+		if !expiration.IsZero() {
+			exp = expiration.Unix()
+		}
+		return broadcastCmd(targets, actionStatus, func(ctx context.Context, ws workspace) error {
+			return ws.setStatus(ctx, rendered, emoji, exp)
+		})()
 	}
 }
 
 func loadTemplatesCmd(path string) tea.Cmd {
 	return func() tea.Msg {
-		data, err := os.ReadFile(path)
+		templates, err := readTemplates(path)
 		if err != nil {
 			return errMsg{err}
 		}
-		var payload templatePayload
-		if err := json.Unmarshal(data, &payload); err != nil {
-			return errMsg{err}
-		}
-		if payload.Templates == nil {
-			payload.Templates = []template{}
+		return templatesMsg(templates)
+	}
+}
+
+func readTemplates(path string) ([]template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var payload templatePayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Templates == nil {
+		payload.Templates = []template{}
+	}
+	// Older files have no ids; assign them once and persist so edits and
+	// deletes can address a template even if labels repeat.
+	if assignTemplateIDs(payload.Templates) {
+		if err := writeTemplates(path, payload.Templates); err != nil {
+			return nil, err
 		}
-		return templatesMsg(payload.Templates)
 	}
+	return payload.Templates, nil
 }
 
 func saveTemplateCmd(path string, existing []template, newTemplate template) tea.Cmd {
 	return func() tea.Msg {
+		if newTemplate.ID == "" {
+			newTemplate.ID = newTemplateID()
+		}
 		list := append([]template{}, existing...)
 		list = append(list, newTemplate)
 		if err := writeTemplates(path, list); err != nil {
@@ -104,11 +117,43 @@ func saveTemplateCmd(path string, existing []template, newTemplate template) tea
 	}
 }
 
-func deleteTemplateCmd(path string, existing []template, label string) tea.Cmd {
+// updateTemplateCmd replaces the template with the same id in place.
+func updateTemplateCmd(path string, existing []template, updated template) tea.Cmd {
+	return func() tea.Msg {
+		list := append([]template{}, existing...)
+		found := false
+		for i := range list {
+			if list[i].ID == updated.ID {
+				list[i] = updated
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errMsg{fmt.Errorf("template %q no longer exists", updated.Label)}
+		}
+		if err := writeTemplates(path, list); err != nil {
+			return errMsg{err}
+		}
+		return savedTemplatesMsg(list)
+	}
+}
+
+// reorderTemplatesCmd persists templates in the given order.
+func reorderTemplatesCmd(path string, ordered []template) tea.Cmd {
+	return func() tea.Msg {
+		if err := writeTemplates(path, ordered); err != nil {
+			return errMsg{err}
+		}
+		return savedTemplatesMsg(ordered)
+	}
+}
+
+func deleteTemplateCmd(path string, existing []template, id string) tea.Cmd {
 	return func() tea.Msg {
 		filtered := make([]template, 0, len(existing))
 		for _, t := range existing {
-			if t.Label != label {
+			if t.ID != id {
 				filtered = append(filtered, t)
 			}
 		}
@@ -119,40 +164,80 @@ func deleteTemplateCmd(path string, existing []template, label string) tea.Cmd {
 	}
 }
 
-func saveConfigCmd(path, token string, confirmDelete bool) tea.Cmd {
+func newTemplateID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+func assignTemplateIDs(templates []template) bool {
+	changed := false
+	seen := make(map[string]bool, len(templates))
+	for i := range templates {
+		if templates[i].ID == "" || seen[templates[i].ID] {
+			templates[i].ID = newTemplateID()
+			changed = true
+		}
+		seen[templates[i].ID] = true
+	}
+	return changed
+}
+
+// saveConfigCmd validates the token and writes cfg with the fields from the
+// settings form or the browser login; other fields of cfg are kept as they
+// are. With workspaces configured the token is the primary workspace's unless
+// cred names another.
+func saveConfigCmd(path string, cfg config, cred credentials, confirmDelete bool) tea.Cmd {
 	return func() tea.Msg {
 		target := configPathForSave(path)
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		client := slack.New(token)
-		if _, err := client.AuthTestContext(ctx); err != nil {
+		health, err := checkToken(ctx, cfg, cred.Token)
+		if err != nil {
 			return errMsg{fmt.Errorf("token validation failed: %w", err)}
 		}
-
-		cfg := config{
-			SlackToken:    token,
-			ConfirmDelete: &confirmDelete,
+		if err := requireScopes(health); err != nil {
+			return errMsg{err}
 		}
 
-		data, err := json.MarshalIndent(cfg, "", "  ")
-		if err != nil {
+		if err := cfg.setCredentials(cred); err != nil {
 			return errMsg{err}
 		}
-		if err := os.WriteFile(target, data, 0o644); err != nil {
+		cfg.ConfirmDelete = &confirmDelete
+
+		if err := writeConfig(target, cfg); err != nil {
 			return errMsg{err}
 		}
 
 		return configUpdatedMsg{
-			cfg:    cfg,
-			client: client,
-			msg:    "Settings saved",
-			path:   target,
+			cfg:  cfg,
+			msg:  "Settings saved",
+			path: target,
 		}
 	}
 }
 
+// writeConfig stores the tokens in the token store and writes the rest;
+// config.json is only readable by the user as it may still hold secrets.
+func writeConfig(path string, cfg config) error {
+	cfg, err := splitSecrets(cfg, path)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0o600)
+}
+
 func writeTemplates(path string, templates []template) error {
 	payload := templatePayload{Templates: templates}
 	data, err := json.MarshalIndent(payload, "", "  ")
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 func resolvePath(name string) (string, error) {
@@ -37,7 +38,26 @@ func ensureTemplatesFile() (string, error) {
 	return target, nil
 }
 
+// errNoToken is returned with the rest of the config, so a first start can
+// still log in through the browser.
+var errNoToken = errors.New("slackToken missing in config.json")
+
 func loadConfig(path string) (config, error) {
+	cfg, err := readConfig(path)
+	if err != nil {
+		return config{}, err
+	}
+	if err := cfg.resolveSecrets(path); err != nil {
+		return config{}, err
+	}
+	if cfg.SlackToken == "" && len(cfg.Workspaces) == 0 {
+		return cfg, errNoToken
+	}
+	return cfg, nil
+}
+
+// readConfig reads config.json as stored, without resolving tokens.
+func readConfig(path string) (config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return config{}, err
@@ -46,9 +66,6 @@ func loadConfig(path string) (config, error) {
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return config{}, err
 	}
-	if cfg.SlackToken == "" {
-		return config{}, errors.New("slackToken missing in config.json")
-	}
 	return cfg, nil
 }
 
@@ -59,6 +76,13 @@ func effectiveConfirmDelete(cfg config) bool {
 	return *cfg.ConfirmDelete
 }
 
+func effectiveRollOver(cfg config) bool {
+	if cfg.RollOverPastUntil == nil {
+		return true
+	}
+	return *cfg.RollOverPastUntil
+}
+
 func defaultConfigPath() string {
 	return filepath.Join("..", configName)
 }
@@ -97,9 +121,20 @@ func loadCalSyncConfig(path string) (calSyncConfig, error) {
 	if cfg.DebugLogPath == "" {
 		cfg.DebugLogPath = "calendar-sync-debug.log"
 	}
+	if cfg.WindowPastDays <= 0 {
+		cfg.WindowPastDays = 1
+	}
+	if cfg.WindowFutureDays <= 0 {
+		cfg.WindowFutureDays = 14
+	}
 	return cfg, nil
 }
 
+// eventWindow returns the time range around now in which parsed events are kept.
+func (cfg calSyncConfig) eventWindow(now time.Time) (time.Time, time.Time) {
+	return now.AddDate(0, 0, -cfg.WindowPastDays), now.AddDate(0, 0, cfg.WindowFutureDays)
+}
+
 func loadSavedStatus(path string) (savedStatus, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/slack-go/slack"
+)
+
+// currentDND reads the user's snooze state.
+func currentDND(ctx context.Context, client *slack.Client) (dndState, error) {
+	st, err := client.GetDNDInfoContext(ctx, nil)
+	if err != nil {
+		return dndState{}, err
+	}
+	return dndStateFrom(st), nil
+}
+
+func dndStateFrom(st *slack.DNDStatus) dndState {
+	s := dndState{Known: true}
+	if st != nil && st.SnoozeEnabled && st.SnoozeEndTime > 0 {
+		s.Snoozing = true
+		s.SnoozeEnd = time.Unix(int64(st.SnoozeEndTime), 0)
+	}
+	return s
+}
+
+func fetchDNDCmd(client *slack.Client) tea.Cmd {
+	return func() tea.Msg {
+		if client == nil {
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		st, err := currentDND(ctx, client)
+		if err != nil {
+			return errMsg{fmt.Errorf("dnd: %w", err)}
+		}
+		return dndMsg(st)
+	}
+}
+
+// snooze starts a snooze until the given time, or ends it when until is zero.
+func snooze(ctx context.Context, client *slack.Client, until time.Time) (dndState, error) {
+	if until.IsZero() {
+		st, err := client.EndSnoozeContext(ctx)
+		if err != nil {
+			return dndState{}, err
+		}
+		return dndStateFrom(st), nil
+	}
+	st, err := client.SetSnoozeContext(ctx, max(int(time.Until(until).Round(time.Minute).Minutes()), 1))
+	if err != nil {
+		return dndState{}, err
+	}
+	return dndStateFrom(st), nil
+}
+
+// snoozeCmd turns on DND in all targets until, or ends it when until is zero.
+func snoozeCmd(targets []workspace, until time.Time) tea.Cmd {
+	return broadcastCmd(targets, actionDND, func(ctx context.Context, ws workspace) error {
+		_, err := snooze(ctx, ws.client, until)
+		return err
+	})
+}
+
+// templateSnoozeCmd snoozes for the duration of a dnd template's status.
+func templateSnoozeCmd(targets []workspace, t template, exp time.Time) tea.Cmd {
+	if !t.DND {
+		return nil
+	}
+	if exp.IsZero() {
+		return func() tea.Msg {
+			return errMsg{fmt.Errorf("%s: dnd needs a duration or until time", t.Label)}
+		}
+	}
+	return snoozeCmd(targets, exp)
+}
+
+// dndLabel renders the snooze state for the status card.
+func dndLabel(s dndState) string {
+	switch {
+	case !s.Known:
+		return "unknown"
+	case s.Snoozing:
+		return "snoozed until " + s.SnoozeEnd.Local().Format("15:04")
+	}
+	return "off"
+}
+
+// ── Manual snooze ────────────────────────────────────────────────────────────
+
+// toggleDND ends a running snooze or asks how long to snooze.
+func (m model) toggleDND() (model, tea.Cmd) {
+	if m.dnd.Snoozing {
+		m.message = "Ending snooze"
+		return m, snoozeCmd(m.workspaces, time.Time{})
+	}
+	m.state = viewSnooze
+	m.message = "Snooze notifications"
+	m.inputs = buildSnoozeInput()
+	m.focusIndex = 0
+	return m, nil
+}
+
+func (m model) submitSnoozeForm() (tea.Model, tea.Cmd) {
+	v := strings.TrimSpace(m.inputs[0].Value())
+	if v == "" {
+		v = "60m"
+	}
+	until, err := parseExpiryExpr(v, time.Now(), effectiveRollOver(m.cfg))
+	if err != nil {
+		return m.withError(err), nil
+	}
+	m.state = viewDashboard
+	m.inputs = nil
+	m.err = nil
+	m.message = "Snoozing until " + formatExpiry(until, time.Now())
+	return m, snoozeCmd(m.workspaces, until)
+}
+
+// snoozePreview resolves the snooze input while typing.
+func (m model) snoozePreview() string {
+	if len(m.inputs) == 0 || strings.TrimSpace(m.inputs[0].Value()) == "" {
+		return ""
+	}
+	now := time.Now()
+	until, err := parseExpiryExpr(m.inputs[0].Value(), now, effectiveRollOver(m.cfg))
+	if err != nil {
+		return "Until: " + err.Error()
+	}
+	return "Until: " + formatExpiry(until, now)
+}
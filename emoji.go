@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ── Emoji set ────────────────────────────────────────────────────────────────
+
+//go:embed emoji.tsv
+var emojiTSV string
+
+// emojiEntry is one emoji of the picker: a standard one with its glyph and
+// shortcodes (the first is the one Slack shows), or a workspace's custom one.
+type emojiEntry struct {
+	Glyph     string
+	Names     []string
+	Workspace string
+	Custom    bool
+}
+
+func (e emojiEntry) name() string { return e.Names[0] }
+
+// standardEmoji is the embedded table, indexed by every shortcode.
+var standardEmoji = sync.OnceValues(func() ([]emojiEntry, map[string]int) {
+	var entries []emojiEntry
+	byName := map[string]int{}
+	sc := bufio.NewScanner(strings.NewReader(emojiTSV))
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		glyph, names, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		e := emojiEntry{Glyph: glyph, Names: strings.Fields(names)}
+		for _, n := range e.Names {
+			byName[n] = len(entries)
+		}
+		entries = append(entries, e)
+	}
+	return entries, byName
+})
+
+// emojiPattern is a Slack shortcode, optionally with a skin tone.
+var emojiPattern = regexp.MustCompile(`^:([a-z0-9_+\-']+):(?::skin-tone-[2-6]:)?$`)
+
+// resolveEmoji checks an emoji field against the standard set and the
+// custom emoji of the workspaces. A glyph or a bare name is turned into its
+// shortcode. Without a loaded custom list, unknown names are let through:
+// they may be custom ones. An unknown name still comes back as a shortcode
+// next to the error, for callers that only warn.
+func (m model) resolveEmoji(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return "", nil
+	}
+	entries, byName := standardEmoji()
+	raw := v
+	if !strings.HasPrefix(v, ":") {
+		bare := strings.TrimSuffix(v, "\ufe0f")
+		for _, e := range entries {
+			if strings.TrimSuffix(e.Glyph, "\ufe0f") == bare {
+				return ":" + e.name() + ":", nil
+			}
+		}
+		v = ":" + strings.Trim(v, ":") + ":"
+	}
+	match := emojiPattern.FindStringSubmatch(strings.ToLower(v))
+	if match == nil {
+		return "", fmt.Errorf("emoji %q: use a shortcode like :coffee:", raw)
+	}
+	v, name := strings.ToLower(v), match[1]
+	if _, ok := byName[name]; ok || m.emoji.Workspaces == nil {
+		return v, nil
+	}
+	for _, custom := range m.emoji.Workspaces {
+		if _, ok := custom[name]; ok {
+			return v, nil
+		}
+	}
+	return v, fmt.Errorf("unknown emoji %s (Ctrl+E opens the picker)", v)
+}
+
+// emojiFieldIndex is the emoji input of a form, -1 if it has none.
+func emojiFieldIndex(state viewState) int {
+	switch state {
+	case viewManual, viewEditCurrent:
+		return 1
+	case viewCreateTemplate, viewEditTemplate:
+		return 2
+	case viewSetLater:
+		return 3
+	case viewVacation:
+		return 5
+	}
+	return -1
+}
+
+// ── Custom emoji ─────────────────────────────────────────────────────────────
+
+const (
+	emojiCacheName  = "emoji-cache.json"
+	emojiRecentName = "emoji-recent.json"
+	emojiCacheTTL   = 24 * time.Hour
+	maxRecentEmoji  = 16
+)
+
+// emojiCache keeps emoji.list per workspace: name → image URL or
+// "alias:<name>".
+type emojiCache struct {
+	FetchedAt  int64                        `json:"fetchedAt"`
+	Workspaces map[string]map[string]string `json:"workspaces"`
+}
+
+func emojiCachePathFor(cfgPath string) string {
+	return filepath.Join(filepath.Dir(configPathForSave(cfgPath)), emojiCacheName)
+}
+
+func emojiRecentPathFor(cfgPath string) string {
+	return filepath.Join(filepath.Dir(configPathForSave(cfgPath)), emojiRecentName)
+}
+
+func loadEmojiCache(path string) (emojiCache, error) {
+	var cache emojiCache
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return cache, err
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return cache, fmt.Errorf("%s: %w", emojiCacheName, err)
+	}
+	return cache, nil
+}
+
+// fresh reports whether the cache is recent and covers all workspaces.
+func (c emojiCache) fresh(workspaces []workspace, now time.Time) bool {
+	if now.Sub(time.Unix(c.FetchedAt, 0)) > emojiCacheTTL {
+		return false
+	}
+	for _, ws := range workspaces {
+		if _, ok := c.Workspaces[ws.name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// fetchCustomEmojiCmd refreshes the custom emoji of every workspace. A
+// workspace that fails (e.g. no emoji:read scope) keeps its cached list.
+func fetchCustomEmojiCmd(path string, cache emojiCache, workspaces []workspace) tea.Cmd {
+	return func() tea.Msg {
+		var mu sync.Mutex
+		lists := map[string]map[string]string{}
+		results := eachWorkspace(workspaces, func(ctx context.Context, ws workspace) error {
+			list, err := ws.client.GetEmojiContext(ctx)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			lists[ws.name] = list
+			mu.Unlock()
+			return nil
+		})
+		if !anySucceeded(results) {
+			return customEmojiMsg{Cache: cache}
+		}
+		for name, list := range cache.Workspaces {
+			if _, ok := lists[name]; !ok {
+				lists[name] = list
+			}
+		}
+		cache = emojiCache{FetchedAt: time.Now().Unix(), Workspaces: lists}
+		data, err := json.Marshal(cache)
+		if err == nil {
+			err = os.WriteFile(path, data, 0o644)
+		}
+		return customEmojiMsg{Cache: cache, Err: err}
+	}
+}
+
+// ── Recently used ────────────────────────────────────────────────────────────
+
+func loadRecentEmoji(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return nil, fmt.Errorf("%s: %w", emojiRecentName, err)
+	}
+	return names, nil
+}
+
+func saveRecentEmojiCmd(path string, names []string) tea.Cmd {
+	return func() tea.Msg {
+		data, err := json.Marshal(names)
+		if err == nil {
+			err = os.WriteFile(path, data, 0o644)
+		}
+		if err != nil {
+			return errMsg{err}
+		}
+		return nil
+	}
+}
+
+// withRecent moves name to the front of the recently used emoji.
+func withRecent(recent []string, name string) []string {
+	out := append([]string{name}, slices.DeleteFunc(slices.Clone(recent), func(n string) bool { return n == name })...)
+	return out[:min(len(out), maxRecentEmoji)]
+}
+
+// ── Search ───────────────────────────────────────────────────────────────────
+
+// emojiChoices is the picker's set: custom emoji of all workspaces, then the
+// standard ones.
+func (m model) emojiChoices() []emojiEntry {
+	standard, _ := standardEmoji()
+	var custom []emojiEntry
+	for _, ws := range m.workspaces {
+		names := make([]string, 0, len(m.emoji.Workspaces[ws.name]))
+		for n := range m.emoji.Workspaces[ws.name] {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			custom = append(custom, emojiEntry{Names: []string{n}, Workspace: ws.name, Custom: true})
+		}
+	}
+	return append(custom, standard...)
+}
+
+// fuzzyScore rates how well name matches query: exact, prefix, word prefix,
+// initials ("hwg" for house_with_garden), substring, then the letters in
+// order; ok is false if it doesn't match.
+func fuzzyScore(name, query string) (int, bool) {
+	switch {
+	case query == "":
+		return 0, true
+	case name == query:
+		return 1000, true
+	case strings.HasPrefix(name, query):
+		return 800 - len(name), true
+	case strings.Contains(name, "_"+query):
+		return 700 - len(name), true
+	case strings.HasPrefix(initials(name), query):
+		return 650 - len(name), true
+	case strings.Contains(name, query):
+		return 600 - strings.Index(name, query) - len(name), true
+	}
+	score, last := 400, -1
+	for _, r := range query {
+		i := strings.IndexRune(name[last+1:], r)
+		if i < 0 {
+			return 0, false
+		}
+		score -= i * 5
+		last += i + 1
+	}
+	return score - len(name), true
+}
+
+// initials are the first letters of the words of a shortcode.
+func initials(name string) string {
+	var b strings.Builder
+	for _, w := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' }) {
+		b.WriteByte(w[0])
+	}
+	return b.String()
+}
+
+// searchEmoji returns the matches for query, best first. Recently used
+// emoji rank higher, so they come first for an empty query.
+func searchEmoji(choices []emojiEntry, recent []string, query string) []emojiEntry {
+	query = strings.ToLower(strings.Trim(strings.TrimSpace(query), ":"))
+	rank := map[string]int{}
+	for i, n := range recent {
+		rank[n] = len(recent) - i
+	}
+	type scored struct {
+		e     emojiEntry
+		score int
+	}
+	var hits []scored
+	seen := map[string]bool{}
+	for _, e := range choices {
+		best, ok := 0, false
+		for _, n := range e.Names {
+			if s, hit := fuzzyScore(n, query); hit && (!ok || s > best) {
+				best, ok = s, true
+			}
+		}
+		if !ok || seen[e.name()] {
+			continue
+		}
+		seen[e.name()] = true
+		hits = append(hits, scored{e, best + rank[e.name()]*20})
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+	out := make([]emojiEntry, len(hits))
+	for i, h := range hits {
+		out[i] = h.e
+	}
+	return out
+}
+
+// ── Rendering ────────────────────────────────────────────────────────────────
+
+// emojiGlyphs turns shortcodes into glyphs in the TUI (config.json
+// "emojiGlyphs", default true); some terminals draw emoji wider than
+// lipgloss measures them and need the shortcodes.
+var emojiGlyphs = true
+
+// shortcodePattern finds shortcodes in status texts, with an optional skin
+// tone as Slack writes it (":wave::skin-tone-3:").
+var shortcodePattern = regexp.MustCompile(`:([a-z0-9_+\-']+):(?::skin-tone-([2-6]):)?`)
+
+func configureEmoji(cfg config) {
+	emojiGlyphs = cfg.EmojiGlyphs == nil || *cfg.EmojiGlyphs
+}
+
+// renderEmoji replaces the standard shortcodes in s (any alias) by their
+// glyph. Custom emoji and unknown names stay shortcodes.
+func renderEmoji(s string) string {
+	if !emojiGlyphs || !strings.Contains(s, ":") {
+		return s
+	}
+	entries, byName := standardEmoji()
+	return shortcodePattern.ReplaceAllStringFunc(s, func(code string) string {
+		match := shortcodePattern.FindStringSubmatch(code)
+		i, ok := byName[match[1]]
+		if !ok {
+			return code
+		}
+		if match[2] == "" {
+			return entries[i].Glyph
+		}
+		return withSkinTone(entries[i].Glyph, int(match[2][0]-'0'))
+	})
+}
+
+// withSkinTone adds the Fitzpatrick modifier for Slack's skin-tone-2…6 after
+// the base emoji; the base's variation selector is dropped, the modifier
+// implies the emoji presentation.
+func withSkinTone(glyph string, tone int) string {
+	runes := []rune(glyph)
+	if len(runes) == 0 {
+		return glyph
+	}
+	rest := runes[1:]
+	if len(rest) > 0 && rest[0] == '\ufe0f' {
+		rest = rest[1:]
+	}
+	return string(runes[0]) + string(rune(0x1F3FB+tone-2)) + string(rest)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pickerRows is how many matches the picker shows at once.
+const pickerRows = 10
+
+// emojiPicker fills the emoji field of the form it was opened from.
+type emojiPicker struct {
+	query   textinput.Model
+	cursor  int
+	results []emojiEntry
+	form    viewState
+	field   int
+}
+
+// openEmojiPicker starts the picker for the focused form, searching for
+// what is already in its emoji field.
+func (m model) openEmojiPicker() (model, tea.Cmd) {
+	field := emojiFieldIndex(m.state)
+	if field < 0 || field >= len(m.inputs) {
+		return m, nil
+	}
+	query := textinput.New()
+	query.Placeholder = "Search emoji"
+	query.Prompt = "› "
+	query.CharLimit = 64
+	query.SetValue(strings.Trim(strings.TrimSpace(m.inputs[field].Value()), ":"))
+	query.Focus()
+	m.picker = &emojiPicker{query: query, form: m.state, field: field}
+	m.picker.results = searchEmoji(m.emojiChoices(), m.recentEmoji, query.Value())
+	m.state = viewEmojiPicker
+	m.message = "Pick an emoji"
+	return m, textinput.Blink
+}
+
+func (m model) handleEmojiPickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.picker
+	switch msg.String() {
+	case "esc":
+		return m.closeEmojiPicker(""), nil
+	case "enter":
+		if len(p.results) == 0 {
+			return m, nil
+		}
+		name := p.results[p.cursor].name()
+		m.recentEmoji = withRecent(m.recentEmoji, name)
+		return m.closeEmojiPicker(":" + name + ":"), saveRecentEmojiCmd(m.emojiRecentPath, m.recentEmoji)
+	case "up", "ctrl+p", "shift+tab":
+		p.cursor = max(p.cursor-1, 0)
+		return m, nil
+	case "down", "ctrl+n", "tab":
+		p.cursor = min(p.cursor+1, max(len(p.results)-1, 0))
+		return m, nil
+	}
+	before := p.query.Value()
+	var cmd tea.Cmd
+	p.query, cmd = p.query.Update(msg)
+	if p.query.Value() != before {
+		p.results = searchEmoji(m.emojiChoices(), m.recentEmoji, p.query.Value())
+		p.cursor = 0
+	}
+	return m, cmd
+}
+
+// closeEmojiPicker returns to the form; a non-empty emoji goes into its
+// emoji field.
+func (m model) closeEmojiPicker(emoji string) model {
+	p := m.picker
+	m.picker = nil
+	m.state = p.form
+	m.message = ""
+	if emoji != "" {
+		m.inputs[p.field].SetValue(emoji)
+		m.inputs[p.field].CursorEnd()
+	}
+	m.focusIndex = p.field
+	for i := range m.inputs {
+		if i == p.field {
+			m.inputs[i].Focus()
+		} else {
+			m.inputs[i].Blur()
+		}
+	}
+	return m
+}
+
+func renderEmojiPicker(p *emojiPicker, workspaces []workspace) string {
+	var b strings.Builder
+	b.WriteString(renderPanelTitle("Emoji"))
+	b.WriteString("\n\n")
+	b.WriteString(p.query.View())
+	b.WriteString("\n\n")
+	if len(p.results) == 0 {
+		b.WriteString("No matching emoji")
+	}
+	start := max(0, min(p.cursor-pickerRows/2, len(p.results)-pickerRows))
+	faint := lipgloss.NewStyle().Faint(true)
+	for i := start; i < min(start+pickerRows, len(p.results)); i++ {
+		e := p.results[i]
+		glyph := e.Glyph
+		if e.Custom {
+			glyph = "▫"
+		}
+		line := fmt.Sprintf("%s  :%s:", glyph, e.name())
+		var extra []string
+		if len(e.Names) > 1 {
+			extra = append(extra, strings.Join(e.Names[1:], ", "))
+		}
+		if e.Custom && len(workspaces) > 1 {
+			extra = append(extra, workspace{name: e.Workspace}.label())
+		} else if e.Custom {
+			extra = append(extra, "custom")
+		}
+		if len(extra) > 0 {
+			line += "  " + faint.Render(strings.Join(extra, " \a "))
+		}
+		if i == p.cursor {
+			line = lipgloss.NewStyle().Foreground(lipgloss.Color("#eed49f")).Bold(true).Render("› " + line)
+		} else {
+			line = "  " + line
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#8aadf4")).Render(
+		fmt.Sprintf("%d matches \a ↑/↓ to choose \a Enter to use \a Esc to go back", len(p.results))))
+	return lipgloss.NewStyle().
+		Border(lipgloss.DoubleBorder()).
+		BorderForeground(lipgloss.Color("#c6a0f6")).
+		Padding(1, 2).
+		Width(80).
+		Render(b.String())
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// absoluteUntilLayouts are the accepted absolute until formats.
+var absoluteUntilLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"02.01.2006 15:04",
+	"02.01.2006",
+	"2006-01-02",
+}
+
+// parseUntil resolves an until value to a point in time. Accepted forms:
+//
+//	16:30                 today; tomorrow once passed if rollover is set
+//	today 16:30           heute 16:30
+//	tomorrow 08:00        morgen 08:00
+//	2026-12-24 09:00      24.12.2026 09:00 (date only = midnight)
+func parseUntil(v string, now time.Time, rollover bool) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	for _, layout := range absoluteUntilLayouts {
+		if t, err := time.ParseInLocation(layout, v, now.Location()); err == nil {
+			if !t.After(now) {
+				return time.Time{}, fmt.Errorf("until %s is in the past", v)
+			}
+			return t, nil
+		}
+	}
+
+	dayOffset := 0
+	clock := v
+	explicitDay := false
+	if day, rest, found := strings.Cut(v, " "); found {
+		switch strings.ToLower(day) {
+		case "today", "heute":
+		case "tomorrow", "morgen":
+			dayOffset = 1
+		case "übermorgen", "uebermorgen":
+			dayOffset = 2
+		default:
+			return time.Time{}, fmt.Errorf("until %q: expected HH:MM, tomorrow HH:MM or YYYY-MM-DD HH:MM", v)
+		}
+		clock = strings.TrimSpace(rest)
+		explicitDay = true
+	}
+
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("until %q: expected HH:MM, tomorrow HH:MM or YYYY-MM-DD HH:MM", v)
+	}
+	target := time.Date(now.Year(), now.Month(), now.Day()+dayOffset, t.Hour(), t.Minute(), 0, 0, now.Location())
+	if !target.After(now) {
+		if explicitDay || !rollover {
+			return time.Time{}, fmt.Errorf("until %s has already passed", v)
+		}
+		target = target.AddDate(0, 0, 1)
+	}
+	return target, nil
+}
+
+// ── Natural-language expressions ─────────────────────────────────────────────
+
+var durationPartRe = regexp.MustCompile(`^(\d+)\s*([a-zäöü]*)\s*`)
+
+// durationUnits maps unit words to their length; "" is handled by the caller.
+var durationUnits = map[string]time.Duration{
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute, "minuten": time.Minute,
+	"h": time.Hour, "std": time.Hour, "hour": time.Hour, "hours": time.Hour, "stunde": time.Hour, "stunden": time.Hour,
+	"d": 24 * time.Hour, "t": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour, "tag": 24 * time.Hour, "tage": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour, "woche": 7 * 24 * time.Hour, "wochen": 7 * 24 * time.Hour,
+}
+
+// parseDurationExpr parses durations such as "90m", "1h30", "2d", "1d 4h" or
+// "2 days". A bare number means minutes, or minutes after an hour part
+// ("1h30").
+func parseDurationExpr(s string) (time.Duration, error) {
+	rest := strings.ToLower(strings.TrimSpace(s))
+	if rest == "" {
+		return 0, fmt.Errorf("duration required")
+	}
+	var total time.Duration
+	var prev time.Duration
+	for rest != "" {
+		m := durationPartRe.FindStringSubmatch(rest)
+		if m == nil {
+			return 0, fmt.Errorf("duration %q: expected e.g. 90m, 1h30 or 2d", s)
+		}
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return 0, err
+		}
+		unit := time.Minute
+		if m[2] != "" {
+			u, ok := durationUnits[m[2]]
+			if !ok {
+				return 0, fmt.Errorf("duration %q: unknown unit %q", s, m[2])
+			}
+			unit = u
+		} else if prev == 24*time.Hour {
+			unit = time.Hour
+		}
+		total += time.Duration(n) * unit
+		prev = unit
+		rest = rest[len(m[0]):]
+	}
+	if total <= 0 {
+		return 0, fmt.Errorf("duration must be greater than 0")
+	}
+	return total, nil
+}
+
+var workdaysRe = regexp.MustCompile(`^(\d+)\s*(workdays?|arbeitstage?|werktage?)$`)
+
+var clockRe = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm|uhr)?$`)
+
+// parseClock accepts "14:00", "9am", "9:30pm" and "14 uhr".
+func parseClock(s string) (int, int, bool) {
+	m := clockRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil || (m[2] == "" && m[3] == "") {
+		return 0, 0, false
+	}
+	h, _ := strconv.Atoi(m[1])
+	min := 0
+	if m[2] != "" {
+		min, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "am":
+		if h == 12 {
+			h = 0
+		}
+	case "pm":
+		if h < 12 {
+			h += 12
+		}
+	}
+	if h > 23 || min > 59 {
+		return 0, 0, false
+	}
+	return h, min, true
+}
+
+// parseExpiryExpr resolves free-form expiry input: durations ("90m", "in 2h"),
+// clock times ("until 14:00", "9am"), weekdays ("friday", "next monday 9am"),
+// "end of day"/"eod", "end of week"/"eow", "tomorrow 08:00", absolute dates
+// and the working-hours forms "next workday", "end of workday" and
+// "3 workdays".
+// Weekdays and "tomorrow" without a time mean the start of that day.
+func parseExpiryExpr(v string, now time.Time, rollover bool) (time.Time, error) {
+	raw := strings.Join(strings.Fields(v), " ")
+	s := strings.ToLower(raw)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("expiry required")
+	}
+	explicitUntil := false
+	for _, prefix := range []string{"until ", "bis ", "in "} {
+		if strings.HasPrefix(s, prefix) {
+			explicitUntil = prefix != "in "
+			s = s[len(prefix):]
+			raw = raw[len(prefix):]
+			break
+		}
+	}
+	if !explicitUntil {
+		if d, err := parseDurationExpr(s); err == nil {
+			return now.Add(d), nil
+		}
+	}
+
+	if m := workdaysRe.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return afterWorkdays(now, n)
+	}
+	switch s {
+	case "eod", "end of day", "tagesende":
+		return startOfDay(now).AddDate(0, 0, 1), nil
+	case "eow", "end of week", "wochenende":
+		days := (int(time.Saturday) - int(now.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return startOfDay(now).AddDate(0, 0, days), nil
+	case "tomorrow", "morgen":
+		return startOfDay(now).AddDate(0, 0, 1), nil
+	case "next workday", "start of next workday", "nächster werktag", "naechster werktag", "werktag", "arbeitsbeginn":
+		return nextWorkdayStart(now), nil
+	case "end of workday", "arbeitsende", "feierabend":
+		return endOfWorkday(now)
+	}
+
+	// "[next] <weekday> [time]"
+	words := strings.Fields(s)
+	if len(words) > 0 && (words[0] == "next" || words[0] == "nächsten" || words[0] == "naechsten" || words[0] == "kommenden") {
+		words = words[1:]
+	}
+	if len(words) > 0 {
+		if wd, ok := weekdayKeys[words[0]]; ok {
+			days := (int(wd) - int(now.Weekday()) + 7) % 7
+			if days == 0 {
+				days = 7
+			}
+			day := startOfDay(now).AddDate(0, 0, days)
+			if len(words) == 1 {
+				return day, nil
+			}
+			h, min, ok := parseClock(strings.Join(words[1:], " "))
+			if !ok {
+				return time.Time{}, fmt.Errorf("expiry %q: unknown time %q", v, strings.Join(words[1:], " "))
+			}
+			return day.Add(time.Duration(h)*time.Hour + time.Duration(min)*time.Minute), nil
+		}
+	}
+
+	if h, min, ok := parseClock(s); ok {
+		return parseUntil(fmt.Sprintf("%02d:%02d", h, min), now, rollover)
+	}
+	if day, rest, found := strings.Cut(s, " "); found {
+		if h, min, ok := parseClock(rest); ok {
+			return parseUntil(fmt.Sprintf("%s %02d:%02d", day, h, min), now, rollover)
+		}
+	}
+	return parseUntil(raw, now, rollover)
+}
+
+// resolveExpiry turns the duration/until pair of a template or form into an
+// expiry. The zero time means no expiry; until wins over duration.
+func resolveExpiry(duration *int, until string, now time.Time, rollover bool) (time.Time, error) {
+	if strings.TrimSpace(until) != "" {
+		return parseExpiryExpr(until, now, rollover)
+	}
+	if duration != nil {
+		if *duration <= 0 {
+			return time.Time{}, fmt.Errorf("duration must be greater than 0")
+		}
+		return now.Add(time.Duration(*duration) * time.Minute), nil
+	}
+	return time.Time{}, nil
+}
+
+// resolveExpiry additionally understands the calendar keywords of untilMeeting.
+func (m model) resolveExpiry(duration *int, until string, now time.Time) (time.Time, error) {
+	if kind, ok := parseUntilMeeting(until); ok {
+		return meetingExpiry(kind, m.calSyncEnabled, m.calSync.events, now)
+	}
+	return resolveExpiry(duration, until, now, effectiveRollOver(m.cfg))
+}
+
+// templateExpiry resolves the expiry t would get if applied at now.
+func (m model) templateExpiry(t template, now time.Time) (time.Time, error) {
+	if t.UntilMeeting != "" {
+		return meetingExpiry(t.UntilMeeting, m.calSyncEnabled, m.calSync.events, now)
+	}
+	until := t.effectiveUntilTime(now)
+	if effectiveRollOver(m.cfg) {
+		// A clock time that already passed today rolls over; the weekday or
+		// holiday override of the day it rolls to applies then.
+		if _, err := resolveExpiry(nil, until, now, false); err != nil {
+			if rolled, err := resolveExpiry(nil, until, now, true); err == nil {
+				day := startOfDay(rolled)
+				return m.resolveExpiry(t.DurationInMinutes, t.effectiveUntilTime(day), day)
+			}
+		}
+	}
+	return m.resolveExpiry(t.DurationInMinutes, until, now)
+}
+
+// formatExpiry renders an expiry relative to now for previews.
+func formatExpiry(exp, now time.Time) string {
+	if exp.IsZero() {
+		return "no expiry"
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day := time.Date(exp.Year(), exp.Month(), exp.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case day.Equal(today):
+		return "heute " + exp.Format("15:04")
+	case day.Equal(today.AddDate(0, 0, 1)):
+		return "morgen " + exp.Format("15:04")
+	}
+	return germanWeekdays[exp.Weekday()][:2] + " " + exp.Format("02.01.2006 15:04")
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestParseDurationExpr(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		err  bool
+	}{
+		{in: "90m", want: 90 * time.Minute},
+		{in: "1h30", want: 90 * time.Minute},
+		{in: "45", want: 45 * time.Minute},
+		{in: "2d", want: 48 * time.Hour},
+		{in: "1d 4h", want: 28 * time.Hour},
+		{in: "1d4", want: 28 * time.Hour},
+		{in: "2 days", want: 48 * time.Hour},
+		{in: "3 Stunden", want: 3 * time.Hour},
+		{in: "", err: true},
+		{in: "0m", err: true},
+		{in: "5 parsecs", err: true},
+		{in: "soon", err: true},
+	}
+	for _, tt := range tests {
+		got, err := parseDurationExpr(tt.in)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("parseDurationExpr(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		in     string
+		h, min int
+		wantOK bool
+	}{
+		{"14:00", 14, 0, true},
+		{"9am", 9, 0, true},
+		{"12am", 0, 0, true},
+		{"12pm", 12, 0, true},
+		{"9:30pm", 21, 30, true},
+		{"14 uhr", 14, 0, true},
+		{"14", 0, 0, false},
+		{"25:00", 0, 0, false},
+		{"9:75", 0, 0, false},
+		{"noon", 0, 0, false},
+	}
+	for _, tt := range tests {
+		h, min, ok := parseClock(tt.in)
+		if ok != tt.wantOK || h != tt.h || min != tt.min {
+			t.Errorf("parseClock(%q) = %d, %d, %v; want %d, %d, %v", tt.in, h, min, ok, tt.h, tt.min, tt.wantOK)
+		}
+	}
+}
+
+func TestParseExpiryExpr(t *testing.T) {
+	loc := mustLoadLocation(t, "Europe/Berlin")
+	// Wednesday, four days before the switch to summer time.
+	now := time.Date(2026, 3, 25, 10, 0, 0, 0, loc)
+	at := func(month time.Month, day, h, min int) time.Time {
+		return time.Date(2026, month, day, h, min, 0, 0, loc)
+	}
+	tests := []struct {
+		in       string
+		rollover bool
+		want     time.Time
+		err      bool
+	}{
+		{in: "90m", want: at(3, 25, 11, 30)},
+		{in: "in 2h", want: at(3, 25, 12, 0)},
+		{in: "until 14:00", want: at(3, 25, 14, 0)},
+		{in: "bis 14 uhr", want: at(3, 25, 14, 0)},
+		{in: "9am", rollover: true, want: at(3, 26, 9, 0)},
+		{in: "until 9:00", rollover: true, want: at(3, 26, 9, 0)},
+		{in: "9am", err: true},
+		{in: "today 08:00", rollover: true, err: true},
+		{in: "tomorrow 08:00", want: at(3, 26, 8, 0)},
+		{in: "morgen 7pm", want: at(3, 26, 19, 0)},
+		{in: "tomorrow", want: at(3, 26, 0, 0)},
+		{in: "eod", want: at(3, 26, 0, 0)},
+		{in: "end of week", want: at(3, 28, 0, 0)},
+		{in: "friday", want: at(3, 27, 0, 0)},
+		{in: "wednesday", want: at(4, 1, 0, 0)},
+		{in: "next monday 9am", want: at(3, 30, 9, 0)},
+		{in: "Freitag 16:30", want: at(3, 27, 16, 30)},
+		{in: "friday 25:00", err: true},
+		{in: "2026-12-24 09:00", want: at(12, 24, 9, 0)},
+		{in: "24.12.2026", want: at(12, 24, 0, 0)},
+		{in: "2026-01-01 09:00", err: true},
+		{in: "", err: true},
+		{in: "soon", err: true},
+	}
+	for _, tt := range tests {
+		got, err := parseExpiryExpr(tt.in, now, tt.rollover)
+		if tt.err {
+			if err == nil {
+				t.Errorf("parseExpiryExpr(%q, rollover %v) = %s, want an error", tt.in, tt.rollover, got)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseExpiryExpr(%q, rollover %v) = %s, %v; want %s", tt.in, tt.rollover, got, err, tt.want)
+		}
+	}
+}
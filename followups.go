@@ -0,0 +1,298 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// clockTickInterval drives time-based features (schedule, follow-ups) while
+// the TUI runs.
+const clockTickInterval = 30 * time.Second
+
+func clockTickCmd() tea.Cmd {
+	return tea.Tick(clockTickInterval, func(t time.Time) tea.Msg {
+		return clockTickMsg(t)
+	})
+}
+
+// followUpsPathFor keeps the follow-up queue next to config.json.
+func followUpsPathFor(cfgPath string) string {
+	return filepath.Join(filepath.Dir(configPathForSave(cfgPath)), followUpsName)
+}
+
+func loadFollowUps(path string) ([]followUp, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var items []followUp
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("%s: %w", followUpsName, err)
+	}
+	return items, nil
+}
+
+func saveFollowUpsCmd(path string, items []followUp) tea.Cmd {
+	items = append([]followUp{}, items...)
+	return func() tea.Msg {
+		if len(items) == 0 {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return followUpsSavedMsg{err}
+			}
+			return followUpsSavedMsg{}
+		}
+		data, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			return followUpsSavedMsg{err}
+		}
+		return followUpsSavedMsg{os.WriteFile(path, data, 0o644)}
+	}
+}
+
+// findTemplate looks a template up by id, then by label, then by label
+// without its leading emoji ("Mittagspause" for "🥗 Mittagspause").
+func findTemplate(templates []template, ref string) (template, bool) {
+	for _, t := range templates {
+		if t.ID == ref {
+			return t, true
+		}
+	}
+	for _, t := range templates {
+		if strings.EqualFold(t.Label, ref) {
+			return t, true
+		}
+	}
+	for _, t := range templates {
+		if strings.EqualFold(labelName(t.Label), labelName(ref)) {
+			return t, true
+		}
+	}
+	return template{}, false
+}
+
+// labelName strips leading symbols and spaces from a template label.
+func labelName(label string) string {
+	return strings.TrimLeftFunc(label, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// snapshotStatus captures a status for a later restore.
+func snapshotStatus(s statusInfo) savedStatus {
+	return savedStatus{
+		Text:           s.Text,
+		Emoji:          s.Emoji,
+		ExpirationUnix: s.ExpirationUnix,
+		SavedAt:        time.Now().Unix(),
+		Presence:       restorablePresence(s.Presence, s.ManualAway),
+	}
+}
+
+// newFollowUp builds the queue entry for t's then, to run at exp. origin is
+// the status before the chain started and is what "previous" restores.
+func newFollowUp(t template, exp time.Time, origin savedStatus, templates []template) (followUp, error) {
+	if exp.IsZero() {
+		return followUp{}, fmt.Errorf("%s: then needs a duration or until time", t.Label)
+	}
+	fu := followUp{ID: newTemplateID(), At: exp.Unix(), ExpectEmoji: t.Emoji, Origin: origin, Workspaces: t.Workspaces}
+	if strings.EqualFold(t.Then, thenPrevious) {
+		fu.Label = "previous: " + missing(origin.Text, "(none)")
+		return fu, nil
+	}
+	next, ok := findTemplate(templates, t.Then)
+	if !ok {
+		return followUp{}, fmt.Errorf("%s: then template %q not found", t.Label, t.Then)
+	}
+	if next.UseDurationSelector {
+		return followUp{}, fmt.Errorf("%s: then template %q asks for a duration and can't run unattended", t.Label, next.Label)
+	}
+	fu.TemplateID = next.ID
+	fu.Label = next.Label
+	return fu, nil
+}
+
+// withFollowUpCmd wraps the status write of a chained template so that its
+// follow-up reaches the model together with the write's result.
+func withFollowUpCmd(set tea.Cmd, item followUp) tea.Cmd {
+	return func() tea.Msg {
+		msg := set()
+		if res, ok := msg.(workspaceResultsMsg); ok {
+			return followUpStatusMsg{workspaceResultsMsg: res, Item: item}
+		}
+		return msg
+	}
+}
+
+// handleFollowUpStatus pushes the follow-up of a chained template once its
+// status was written (or queued offline) in at least one workspace.
+func (m model) handleFollowUpStatus(msg followUpStatusMsg) (model, tea.Cmd) {
+	var save tea.Cmd
+	for _, r := range msg.Results {
+		if r.Err == nil || errors.Is(r.Err, errQueued) {
+			m.followUps = append(m.followUps, msg.Item)
+			save = saveFollowUpsCmd(m.followUpsPath, m.followUps)
+			break
+		}
+	}
+	m, cmd := m.handleWorkspaceResults(msg.workspaceResultsMsg)
+	return m, tea.Batch(cmd, save)
+}
+
+// checkFollowUpCmd reads the current status in every target workspace so
+// the model can decide where the due follow-up still applies.
+func checkFollowUpCmd(targets []workspace, item followUp) tea.Cmd {
+	return func() tea.Msg {
+		current, err := currentStatuses(targets)
+		return followUpCheckedMsg{Item: item, Current: current, Err: err}
+	}
+}
+
+// dueFollowUp returns the topmost follow-up whose time has come; the stack
+// grows at the end.
+func dueFollowUp(items []followUp, now time.Time) (followUp, bool) {
+	for i := len(items) - 1; i >= 0; i-- {
+		if items[i].At <= now.Unix() {
+			return items[i], true
+		}
+	}
+	return followUp{}, false
+}
+
+func withoutFollowUp(items []followUp, id string) []followUp {
+	out := make([]followUp, 0, len(items))
+	for _, fu := range items {
+		if fu.ID != id {
+			out = append(out, fu)
+		}
+	}
+	return out
+}
+
+// handleClockTick runs due scheduled rules, the vacation plan, queued
+// statuses and follow-ups. While cal-sync
+// shows a meeting the follow-up waits, so it runs after the meeting status is
+// restored.
+func (m model) handleClockTick(now time.Time) (tea.Model, tea.Cmd) {
+	m, scheduled := m.handleScheduleTick(now)
+	m, vacationCmd := m.handleVacationTick(now)
+	cmds := []tea.Cmd{clockTickCmd(), scheduled, vacationCmd}
+	if m.calSync.ActiveEventID == "" {
+		cmds = append(cmds, claimDueQueuedCmd(m.queuePath, now))
+	} else {
+		cmds = append(cmds, loadQueueCmd(m.queuePath))
+	}
+	if !m.refreshingTokens && !now.Before(m.refresh.retryAt) {
+		if cmd := refreshTokensCmd(m.configPath, m.cfg, now, m.refresh.revoked); cmd != nil {
+			m.refreshingTokens = true
+			cmds = append(cmds, cmd)
+		}
+	}
+	if !m.replayingOffline && len(m.workspaces) > 0 {
+		m.replayingOffline = true
+		cmds = append(cmds, replayOfflineQueueCmd(m.offlineQueuePath, m.workspaces))
+	}
+	if fu, ok := dueFollowUp(m.followUps, now); ok && m.calSync.ActiveEventID == "" && !m.followUpChecking {
+		targets, err := m.targetsFor(fu.Workspaces)
+		if err != nil {
+			m.err = fmt.Errorf("follow-up %s: %w", fu.Label, err)
+			m.followUps = withoutFollowUp(m.followUps, fu.ID)
+			cmds = append(cmds, saveFollowUpsCmd(m.followUpsPath, m.followUps))
+		} else {
+			m.followUpChecking = true
+			cmds = append(cmds, checkFollowUpCmd(targets, fu))
+		}
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m model) handleFollowUpChecked(msg followUpCheckedMsg) (tea.Model, tea.Cmd) {
+	m.followUpChecking = false
+	if msg.Err != nil {
+		m.err = fmt.Errorf("follow-up %s: %w", msg.Item.Label, msg.Err)
+		return m, nil
+	}
+	fu := msg.Item
+	m.followUps = withoutFollowUp(m.followUps, fu.ID)
+	save := saveFollowUpsCmd(m.followUpsPath, m.followUps)
+
+	// Where someone set a different status in the meantime the chain is over.
+	changed := map[string]bool{}
+	for name, cur := range msg.Current {
+		if cur.Emoji != "" && cur.Emoji != fu.ExpectEmoji {
+			changed[name] = true
+		}
+	}
+	if len(changed) == len(msg.Current) {
+		m.message = "Follow-up skipped, status was changed: " + fu.Label
+		return m, save
+	}
+	if len(m.workspaces) > 0 {
+		if cur, ok := msg.Current[m.workspaces[0].name]; ok {
+			m.status.Text, m.status.Emoji, m.status.ExpirationUnix = cur.Text, cur.Emoji, cur.ExpirationUnix
+		}
+	}
+	unchanged := func(targets []workspace) []workspace {
+		var out []workspace
+		for _, ws := range targets {
+			if !changed[ws.name] {
+				out = append(out, ws)
+			}
+		}
+		return out
+	}
+
+	if fu.TemplateID == "" {
+		exp := time.Time{}
+		if fu.Origin.ExpirationUnix > 0 {
+			exp = time.Unix(fu.Origin.ExpirationUnix, 0)
+			if !exp.After(time.Now()) {
+				m.message = "Follow-up skipped, previous status has expired"
+				return m, save
+			}
+		}
+		targets, err := m.targetsFor(fu.Workspaces)
+		if err != nil {
+			m.err = fmt.Errorf("follow-up %s: %w", fu.Label, err)
+			return m, save
+		}
+		targets = unchanged(targets)
+		m.message = "Follow-up: " + fu.Label
+		return m, tea.Batch(save, setStatusCmd(targets, fu.Origin.Text, fu.Origin.Emoji, exp, m.textVars()), setPresenceCmd(targets, fu.Origin.Presence))
+	}
+
+	t, ok := findTemplate(m.templates, fu.TemplateID)
+	if !ok {
+		m.err = fmt.Errorf("follow-up template %q no longer exists", fu.Label)
+		return m, save
+	}
+	if len(changed) > 0 {
+		targets, err := m.targetsFor(t.Workspaces)
+		if err != nil {
+			m.err = fmt.Errorf("follow-up %s: %w", fu.Label, err)
+			return m, save
+		}
+		t.Workspaces = nil
+		for _, ws := range unchanged(targets) {
+			t.Workspaces = append(t.Workspaces, ws.name)
+		}
+		if len(t.Workspaces) == 0 {
+			m.message = "Follow-up skipped, status was changed: " + fu.Label
+			return m, save
+		}
+	}
+	m.message = "Follow-up: " + t.Label
+	origin := fu.Origin
+	m, cmd := m.applyTemplateInChain(t, &origin)
+	return m, tea.Batch(save, cmd)
+}
@@ -6,21 +6,37 @@ import (
 	"strings"
 	"time"
 
-	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func (m model) Init() tea.Cmd {
 	var cmds []tea.Cmd
 	if m.client != nil {
-		cmds = append(cmds, fetchStatusCmd(m.client))
+		cmds = append(cmds, m.fetchStatusesCmd(), fetchPresenceCmd(m.client), fetchDNDCmd(m.client), checkHealthCmd(m.cfg, m.workspaces))
 	}
 	if m.templatesPath != "" {
 		cmds = append(cmds, loadTemplatesCmd(m.templatesPath))
 	}
 	if m.calSyncEnabled && m.client != nil {
-		cmds = append(cmds, pollCalendarCmd(m.calSyncCfg))
+		cmds = append(cmds, pollCalendarCmd(m.calSyncCfg, ""))
 	}
+	if m.refreshingTokens {
+		cmds = append(cmds, refreshTokensCmd(m.configPath, m.cfg, time.Now(), nil))
+	}
+	if m.client != nil && !m.emoji.fresh(m.workspaces, time.Now()) {
+		cmds = append(cmds, fetchCustomEmojiCmd(m.emojiCachePath, m.emoji, m.workspaces))
+	}
+	if m.client != nil {
+		cmds = append(cmds, livePollCmd(livePollInterval(m.cfg)))
+		if m.cfg.LiveStatus != nil && m.cfg.LiveStatus.AppToken != "" {
+			cmds = append(cmds, socketModeCmd(m.cfg.LiveStatus.AppToken, m.cfg, m.workspaces))
+		}
+	}
+	if m.replayingOffline {
+		cmds = append(cmds, replayOfflineQueueCmd(m.offlineQueuePath, m.workspaces))
+	}
+	cmds = append(cmds, clockTickCmd())
 	return tea.Batch(cmds...)
 }
 
@@ -32,61 +48,126 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.templateList.SetSize(w, h)
 		m.durationList.SetSize(w, h)
 	case statusMsg:
+		m = m.noteStatus(statusInfo(msg), time.Now())
+		delete(m.workspaceErrs, msg.Workspace)
+		if len(m.workspaces) > 0 && msg.Workspace != m.workspaces[0].name {
+			return m, nil
+		}
+		presence := m.status.Presence
 		m.status = statusInfo(msg)
+		m.status.Presence = presence
 		m.message = "Status refreshed"
 		m.err = nil
 	case templatesMsg:
 		m.templates = msg
-		items := make([]list.Item, 0, len(msg))
-		for _, t := range msg {
-			tCopy := t
-			items = append(items, templateItem(tCopy))
-		}
-		m.templateList.SetItems(items)
-		if len(items) == 0 {
+		m = m.refreshTemplateList()
+		if len(msg) == 0 {
 			m.templateList.Title = "Status Templates (empty - press c to add)"
 		} else {
 			m.templateList.Title = "Status Templates"
 		}
 		m.message = "Templates loaded"
-		m.err = nil
+		m.err = validateTemplates(msg)
 	case savedTemplatesMsg:
 		m.state = viewDashboard
 		m.inputs = nil
 		m.focusIndex = 0
 		return m, tea.Batch(loadTemplatesCmd(m.templatesPath), messageCmd("Templates saved"))
+	case presenceMsg:
+		m.status.Presence, m.status.ManualAway = msg.Presence, msg.ManualAway
+		return m, nil
+	case dndMsg:
+		m.dnd = dndState(msg)
+		return m, nil
+	case customEmojiMsg:
+		m.emoji = msg.Cache
+		if msg.Err != nil {
+			m.err = msg.Err
+		}
+		return m, nil
+	case liveStatusMsg:
+		return m.handleLiveStatus(statusInfo(msg)), nil
+	case livePollMsg:
+		return m, tea.Batch(m.fetchLiveStatusesCmd(), livePollCmd(livePollInterval(m.cfg)))
+	case liveEventMsg:
+		return m, m.fetchLiveStatusesCmd()
+	case offlineQueueMsg:
+		return m.handleOfflineQueue(msg)
+	case healthMsg:
+		m.health = []tokenHealth(msg)
+		if err := healthError(m.health); err != nil {
+			m.err = err
+		}
+		return m, nil
 	case setStatusMsg:
 		m.message = string(msg)
-		if m.client != nil {
-			return m, fetchStatusCmd(m.client)
-		}
 		return m, nil
+	case workspaceResultsMsg:
+		return m.handleWorkspaceResults(msg)
+	case followUpStatusMsg:
+		return m.handleFollowUpStatus(msg)
 	case errMsg:
 		m.err = msg.err
 		m.message = ""
 	case configUpdatedMsg:
-		m.cfg = msg.cfg
-		m.confirmDelete = effectiveConfirmDelete(msg.cfg)
-		m.client = msg.client
-		m.configPath = msg.path
+		m.loginURL = ""
 		m.message = msg.msg
 		m.err = nil
-		if m.client != nil {
-			return m, fetchStatusCmd(m.client)
+		return m.applyConfig(msg.cfg, msg.path)
+	case loginStartedMsg:
+		m.loginURL = msg.URL
+		m.message = "Waiting for the Slack login in your browser"
+		return m, nil
+	case loginFailedMsg:
+		m.loginURL = ""
+		m.err = msg.err
+		return m, nil
+	case tokensRefreshedMsg:
+		m.refreshingTokens = false
+		m.refresh = m.refresh.noteRefresh(msg, time.Now())
+		if msg.path == "" {
+			return m.withError(msg.err), nil
+		}
+		m, cmd := m.applyConfig(msg.cfg, msg.path)
+		if msg.err != nil {
+			m.err = msg.err
+		}
+		return m, cmd
+
+	case clockTickMsg:
+		return m.handleClockTick(time.Time(msg))
+
+	case followUpCheckedMsg:
+		return m.handleFollowUpChecked(msg)
+
+	case queueLoadedMsg:
+		return m.handleQueueLoaded(msg)
+
+	case vacationCheckedMsg:
+		return m.handleVacationChecked(msg)
+
+	case followUpsSavedMsg:
+		if msg.err != nil {
+			m.err = fmt.Errorf("saving follow-ups: %w", msg.err)
 		}
 		return m, nil
 
 	// ── Calendar sync messages ──────────────────────────────────────────
 	case calSyncTickMsg:
 		if m.calSyncEnabled {
-			return m, pollCalendarCmd(m.calSyncCfg)
+			return m, pollCalendarCmd(m.calSyncCfg, m.calSync.reusableFeedHash(time.Now()))
 		}
 		return m, nil
 
 	case calEventsMsg:
 		m.calSync.LastPollAt = msg.FetchedAt
 		m.calSync.LastPollErr = nil
-		return m.handleCalEvents(filterActiveEvents(msg.Events, msg.FetchedAt), msg.FetchedAt)
+		if !msg.Unchanged {
+			m.calSync.events = msg.Events
+			m.calSync.feedHash = msg.Hash
+			m.calSync.parsedAt = msg.FetchedAt
+		}
+		return m.handleCalEvents(filterActiveEvents(m.calSync.events, msg.FetchedAt), msg.FetchedAt)
 
 	case calStatusSavedMsg:
 		m.calSync.StatusSaved = true
@@ -94,16 +175,22 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.calSync.pendingEvent != nil {
 			ev := *m.calSync.pendingEvent
 			m.calSync.pendingEvent = nil
-			return m, setMeetingStatusCmd(m.client, m.calSyncCfg, ev)
+			return m, setMeetingStatusCmd(m.calSyncTargets(), m.calSyncCfg, ev)
 		}
 		return m, nil
 
 	case calStatusSetMsg:
 		m.calSync.ActiveEventID = msg.EventID
 		m.calSync.ActiveEventEnd = msg.EventEnd
+		m.calSync.overridden = nil
+		m.markOwnWrite(workspaceNames(m.calSyncTargets())...)
+		if msg.Err != nil {
+			m.err = msg.Err
+		}
 		interval := time.Duration(m.calSyncCfg.PollingIntervalSeconds) * time.Second
 		return m, tea.Batch(
-			fetchStatusCmd(m.client),
+			m.fetchStatusesCmd(),
+			fetchDNDCmd(m.client),
 			startCalSyncTickCmd(interval),
 		)
 
@@ -113,9 +200,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.calSync.StatusSaved = false
 		m.calSync.StatusSavedText = ""
 		m.calSync.pendingEvent = nil
+		m.calSync.overridden = nil
+		m.markOwnWrite(workspaceNames(m.calSyncTargets())...)
+		if msg.Err != nil {
+			m.err = msg.Err
+		}
 		interval := time.Duration(m.calSyncCfg.PollingIntervalSeconds) * time.Second
 		return m, tea.Batch(
-			fetchStatusCmd(m.client),
+			m.fetchStatusesCmd(),
+			fetchPresenceCmd(m.client),
+			fetchDNDCmd(m.client),
 			startCalSyncTickCmd(interval),
 		)
 
@@ -163,22 +257,61 @@ func (m model) handleDashboardKey(msg tea.KeyMsg) (model, tea.Cmd, bool) {
 	case "ctrl+c", "q":
 		return m, tea.Quit, true
 	case "r":
-		return m, tea.Batch(fetchStatusCmd(m.client), loadTemplatesCmd(m.templatesPath), messageCmd("Refreshing.")), true
-	case "enter":
+		return m, tea.Batch(m.fetchStatusesCmd(), fetchPresenceCmd(m.client), fetchDNDCmd(m.client), loadTemplatesCmd(m.templatesPath), messageCmd("Refreshing.")), true
+	case "enter", " ":
+		if h, ok := m.templateList.SelectedItem().(groupHeaderItem); ok {
+			return m.toggleGroup(h.Name), nil, true
+		}
+		if msg.String() == " " {
+			return m, nil, false
+		}
 		item := m.selectedTemplate()
 		if item == nil {
 			return m, nil, true
 		}
-		if item.UseDurationSelector {
-			return m.enterDurationSelector(*item), nil, true
-		}
-		return m, setStatusCmd(m.client, item.Text, item.Emoji, item.DurationInMinutes, item.UntilTime), true
+		m, cmd := m.applyTemplate(*item)
+		return m, cmd, true
 	case "a", "n":
 		return m.enterManualForm(), nil, true
 	case "e":
 		return m.enterEditForm(), nil, true
 	case "c":
 		return m.enterCreateTemplateForm(), nil, true
+	case "E":
+		t := m.selectedTemplate()
+		if t == nil {
+			return m, nil, true
+		}
+		return m.enterEditTemplateForm(*t), nil, true
+	case "f":
+		t := m.selectedTemplate()
+		if t == nil {
+			return m, nil, true
+		}
+		t.Favorite = !t.Favorite
+		m.selectTemplateID = t.ID
+		return m, updateTemplateCmd(m.templatesPath, m.templates, *t), true
+	case "m":
+		t := m.selectedTemplate()
+		if t == nil {
+			return m, nil, true
+		}
+		return m.enterMoveTemplateGroupForm(*t), nil, true
+	case "K", "shift+up", "J", "shift+down":
+		t := m.selectedTemplate()
+		if t == nil {
+			return m, nil, true
+		}
+		dir := 1
+		if msg.String() == "K" || msg.String() == "shift+up" {
+			dir = -1
+		}
+		moved, ok := moveTemplate(m.templates, t.ID, dir)
+		if !ok {
+			return m, nil, true
+		}
+		m.selectTemplateID = t.ID
+		return m, reorderTemplatesCmd(m.templatesPath, moved), true
 	case "x", "delete", "backspace":
 		if m.selectedTemplate() == nil {
 			return m, nil, true
@@ -186,7 +319,7 @@ func (m model) handleDashboardKey(msg tea.KeyMsg) (model, tea.Cmd, bool) {
 		if !m.confirmDelete {
 			t := m.selectedTemplate()
 			if t != nil {
-				return m, deleteTemplateCmd(m.templatesPath, m.templates, t.Label), true
+				return m, deleteTemplateCmd(m.templatesPath, m.templates, t.ID), true
 			}
 			return m, nil, true
 		}
@@ -194,21 +327,48 @@ func (m model) handleDashboardKey(msg tea.KeyMsg) (model, tea.Cmd, bool) {
 		m.message = "Delete selected template? (y/n)"
 		return m, nil, true
 	case "s":
-		return m.enterSettings(), nil, true
+		m, cmd := m.enterSettings()
+		return m, cmd, true
 	case "C":
 		if m.calSyncEnabled {
 			m.state = viewCalSyncStatus
 			return m, nil, true
 		}
 		return m, nil, false
+	case "L":
+		m, cmd := m.enterQueueView()
+		return m, cmd, true
+	case "V":
+		return m.enterVacationForm(), nil, true
+	case "D":
+		m, cmd := m.toggleDND()
+		return m, cmd, true
 	case "?":
-		m.message = "Keys: enter use template \a a manual \a e edit current \a c create template \a x delete \a s settings \a C cal-sync \a r refresh \a q quit"
+		m.message = "Keys: enter use template \a a manual \a e edit current \a c create template \a E edit template \a f favorite \a J/K move \a m move to group \a x delete \a <hotkey> apply template \a L set later \a V vacation \a D snooze on/off \a s settings \a C cal-sync \a r refresh \a q quit"
 		return m, nil, true
 	}
+	if t, ok := templateForKey(m.templates, msg.String()); ok {
+		m, cmd := m.applyTemplate(t)
+		return m, cmd, true
+	}
 	return m, nil, false
 }
 
+func (m model) toggleGroup(name string) model {
+	if m.collapsedGroups == nil {
+		m.collapsedGroups = map[string]bool{}
+	}
+	m.collapsedGroups[name] = !m.collapsedGroups[name]
+	idx := m.templateList.Index()
+	m = m.refreshTemplateList()
+	m.templateList.Select(idx)
+	return m
+}
+
 func (m model) handleFormKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.state == viewEmojiPicker {
+		return m.handleEmojiPickerKey(msg)
+	}
 	if m.state == viewDurationSelector {
 		return m.handleDurationSelectorKey(msg)
 	}
@@ -221,6 +381,13 @@ func (m model) handleFormKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 	}
+	if m.state == viewQueue {
+		return m.handleQueueKey(msg)
+	}
+	if m.state == viewSetLater && msg.String() == "esc" {
+		m, cmd := m.enterQueueView()
+		return m, cmd
+	}
 	switch msg.String() {
 	case "esc":
 		return m.backToDashboard(), nil
@@ -230,7 +397,7 @@ func (m model) handleFormKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if msg.String() == "y" {
 			t := m.selectedTemplate()
 			if t != nil {
-				return m, deleteTemplateCmd(m.templatesPath, m.templates, t.Label)
+				return m, deleteTemplateCmd(m.templatesPath, m.templates, t.ID)
 			}
 		}
 		return m.backToDashboard(), nil
@@ -257,16 +424,50 @@ func (m model) handleFormKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 		return m, nil
+	case "ctrl+e":
+		if emojiFieldIndex(m.state) >= 0 {
+			return m.openEmojiPicker()
+		}
 	case "enter":
+		if field := emojiFieldIndex(m.state); field >= 0 && field < len(m.inputs) {
+			emoji, err := m.resolveEmoji(m.inputs[field].Value())
+			if err != nil {
+				return m.withError(err), nil
+			}
+			m.inputs[field].SetValue(emoji)
+		}
 		if m.state == viewSettings {
 			return m.submitSettingsForm()
 		}
+		if m.state == viewSetLater {
+			return m.submitSetLaterForm()
+		}
+		if m.state == viewVacation {
+			return m.submitVacationForm()
+		}
+		if m.state == viewSnooze {
+			return m.submitSnoozeForm()
+		}
 		return m.submitForm()
 	case "t", " ":
 		if m.state == viewSettings {
 			m.confirmDelete = !m.confirmDelete
 			return m, nil
 		}
+	case "ctrl+r":
+		if m.state == viewSettings && len(m.inputs) > 0 {
+			if m.inputs[0].EchoMode == textinput.EchoPassword {
+				m.inputs[0].EchoMode = textinput.EchoNormal
+			} else {
+				m.inputs[0].EchoMode = textinput.EchoPassword
+			}
+			return m, nil
+		}
+	case "ctrl+o":
+		if m.state == viewSettings {
+			m.message = "Starting Slack login"
+			return m, loginCmd(m.configPath, m.cfg, m.confirmDelete)
+		}
 	}
 
 	cmd := m.updateInputs(msg)
@@ -282,9 +483,28 @@ func (m model) handleDurationSelectorKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if !ok {
 			return m, nil
 		}
-		if item.Unit == durationNextMonday {
-			minutes := minutesUntilNextMonday(time.Now())
-			return m.applyDurationMinutes(minutes)
+		switch item.Unit {
+		case durationNextMonday:
+			return m.applyDurationUntil(nextWeekStart(time.Now()))
+		case durationNextWorkday:
+			return m.applyDurationUntil(nextWorkdayStart(time.Now()))
+		case durationEndOfWorkday:
+			exp, err := endOfWorkday(time.Now())
+			if err != nil {
+				return m.withError(err), nil
+			}
+			return m.applyDurationUntil(exp)
+		}
+		if item.Unit == durationNextMeeting || item.Unit == durationMeetingEnd {
+			kind := untilNextMeeting
+			if item.Unit == durationMeetingEnd {
+				kind = untilMeetingEnd
+			}
+			exp, err := meetingExpiry(kind, m.calSyncEnabled, m.calSync.events, time.Now())
+			if err != nil {
+				return m.withError(err), nil
+			}
+			return m.applyDurationUntil(exp)
 		}
 		m.state = viewDurationValue
 		m.durationUnit = item.Unit
@@ -326,12 +546,28 @@ func (m model) submitDurationValue() (tea.Model, tea.Cmd) {
 	if len(m.inputs) == 0 {
 		return m.withError(errors.New("duration value missing")), nil
 	}
-	value, err := parsePositiveInt(m.inputs[0].Value())
+	exp, err := m.durationValueExpiry(time.Now())
 	if err != nil {
 		return m.withError(fmt.Errorf("duration: %w", err)), nil
 	}
+	return m.applyDurationUntil(exp)
+}
+
+// durationValueExpiry resolves the value input of the duration selector for
+// the chosen unit; durationCustom accepts any expiry expression.
+func (m model) durationValueExpiry(now time.Time) (time.Time, error) {
+	raw := m.inputs[0].Value()
+	if m.durationUnit == durationCustom {
+		return parseExpiryExpr(raw, now, effectiveRollOver(m.cfg))
+	}
+	value, err := parsePositiveInt(raw)
+	if err != nil {
+		return time.Time{}, err
+	}
 	minutes := value
 	switch m.durationUnit {
+	case durationWorkdays:
+		return afterWorkdays(now, value)
 	case durationDays:
 		minutes = value * 24 * 60
 	case durationHours:
@@ -339,37 +575,71 @@ func (m model) submitDurationValue() (tea.Model, tea.Cmd) {
 	case durationMinutes:
 		minutes = value
 	}
-	return m.applyDurationMinutes(minutes)
+	return now.Add(time.Duration(minutes) * time.Minute), nil
 }
 
-func (m model) applyDurationMinutes(minutes int) (tea.Model, tea.Cmd) {
+func (m model) applyDurationUntil(expiry time.Time) (tea.Model, tea.Cmd) {
 	if m.pendingTemplate == nil {
 		return m.withError(errors.New("no template selected")), nil
 	}
-	if minutes <= 0 {
-		return m.withError(errors.New("duration must be greater than 0")), nil
-	}
 	t := *m.pendingTemplate
-	duration := minutes
 	m.state = viewDashboard
 	m.inputs = nil
 	m.focusIndex = 0
 	m.pendingTemplate = nil
-	return m, setStatusCmd(m.client, t.Text, t.Emoji, &duration, "")
+	return m.setStatusWithFollowUp(t, expiry, nil)
+}
+
+// applyTemplate sets the status described by t, opening the duration selector
+// or resolving calendar-based expiries first where the template asks for it.
+func (m model) applyTemplate(t template) (model, tea.Cmd) {
+	return m.applyTemplateInChain(t, nil)
+}
+
+// applyTemplateInChain is applyTemplate for a template that may be part of a
+// then-chain; origin is the status before the chain (nil = the current one).
+// A template with a then pushes its follow-up onto the stack once the status
+// is set; the follow-ups below it stay pending.
+func (m model) applyTemplateInChain(t template, origin *savedStatus) (model, tea.Cmd) {
+	if t.UseDurationSelector {
+		return m.enterDurationSelector(t), nil
+	}
+	exp, err := m.templateExpiry(t, time.Now())
+	if err != nil {
+		return m.withError(fmt.Errorf("%s: %w", t.Label, err)), nil
+	}
+	return m.setStatusWithFollowUp(t, exp, origin)
 }
 
-func minutesUntilNextMonday(now time.Time) int {
-	daysUntil := (int(time.Monday) - int(now.Weekday()) + 7) % 7
-	if daysUntil == 0 {
-		daysUntil = 7
+func (m model) setStatusWithFollowUp(t template, exp time.Time, origin *savedStatus) (model, tea.Cmd) {
+	targets, err := m.targetsFor(t.Workspaces)
+	if err != nil {
+		return m.withError(fmt.Errorf("%s: %w", t.Label, err)), nil
+	}
+	// Only the forms insist on a known emoji: the table may lack names Slack
+	// has, and a template that worked before must keep working.
+	if emoji, err := m.resolveEmoji(t.Emoji); err != nil {
+		m.err = fmt.Errorf("%s: %w, sent as is", t.Label, err)
+	} else {
+		t.Emoji = emoji
 	}
-	target := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	target = target.AddDate(0, 0, daysUntil)
-	minutes := int(target.Sub(now).Minutes())
-	if minutes < 1 {
-		return 1
+	set := setStatusCmd(targets, t.Text, t.Emoji, exp, m.textVars())
+	if t.Then != "" {
+		o := snapshotStatus(m.status)
+		if origin != nil {
+			o = *origin
+		}
+		fu, err := newFollowUp(t, exp, o, m.templates)
+		if err != nil {
+			return m.withError(err), nil
+		}
+		set = withFollowUpCmd(set, fu)
 	}
-	return minutes
+	return m, tea.Batch(
+		set,
+		setPresenceCmd(targets, t.Presence),
+		templateSnoozeCmd(targets, t, exp),
+	)
 }
 
 func (m model) submitForm() (tea.Model, tea.Cmd) {
@@ -377,41 +647,133 @@ func (m model) submitForm() (tea.Model, tea.Cmd) {
 	case viewManual, viewEditCurrent:
 		text := strings.TrimSpace(m.inputs[0].Value())
 		emoji := strings.TrimSpace(m.inputs[1].Value())
-		duration, err := parseOptionalInt(m.inputs[2].Value())
+		duration, err := parseOptionalMinutes(m.inputs[2].Value())
 		if err != nil {
 			return m.withError(fmt.Errorf("duration: %w", err)), nil
 		}
 		until := strings.TrimSpace(m.inputs[3].Value())
+		presence, err := parsePresence(m.inputs[4].Value())
+		if err != nil {
+			return m.withError(err), nil
+		}
 		if text == "" || emoji == "" {
 			return m.withError(errors.New("text and emoji are required")), nil
 		}
+		exp, err := m.resolveExpiry(duration, until, time.Now())
+		if err != nil {
+			return m.withError(err), nil
+		}
 		m.state = viewDashboard
-		return m, setStatusCmd(m.client, text, emoji, duration, until)
+		m.followUps = nil
+		return m, tea.Batch(
+			setStatusCmd(m.workspaces, text, emoji, exp, m.textVars()),
+			setPresenceCmd(m.workspaces, presence),
+			saveFollowUpsCmd(m.followUpsPath, m.followUps),
+		)
 	case viewCreateTemplate:
-		label := strings.TrimSpace(m.inputs[0].Value())
-		text := strings.TrimSpace(m.inputs[1].Value())
-		emoji := strings.TrimSpace(m.inputs[2].Value())
-		duration, err := parseOptionalInt(m.inputs[3].Value())
+		t, err := templateFromInputs(m.inputs)
 		if err != nil {
-			return m.withError(fmt.Errorf("duration: %w", err)), nil
+			return m.withError(err), nil
 		}
-		until := strings.TrimSpace(m.inputs[4].Value())
-		if label == "" || text == "" || emoji == "" {
-			return m.withError(errors.New("label, text, and emoji are required")), nil
+		m.state = viewDashboard
+		return m, saveTemplateCmd(m.templatesPath, m.templates, t)
+	case viewMoveTemplateGroup:
+		var t *template
+		for i := range m.templates {
+			if m.templates[i].ID == m.editingTemplateID {
+				tCopy := m.templates[i]
+				t = &tCopy
+				break
+			}
+		}
+		if t == nil {
+			return m.withError(errors.New("template no longer exists")), nil
+		}
+		t.Group = strings.TrimSpace(m.inputs[0].Value())
+		m.state = viewDashboard
+		m.editingTemplateID = ""
+		m.selectTemplateID = t.ID
+		return m, updateTemplateCmd(m.templatesPath, m.templates, *t)
+	case viewEditTemplate:
+		t, err := templateFromInputs(m.inputs)
+		if err != nil {
+			return m.withError(err), nil
 		}
-		newTemplate := template{
-			Label:             label,
-			Text:              text,
-			Emoji:             emoji,
-			DurationInMinutes: duration,
-			UntilTime:         until,
+		t.ID = m.editingTemplateID
+		for _, existing := range m.templates {
+			if existing.ID == t.ID {
+				t.Favorite = existing.Favorite
+			}
 		}
 		m.state = viewDashboard
-		return m, saveTemplateCmd(m.templatesPath, m.templates, newTemplate)
+		m.selectTemplateID = t.ID
+		m.editingTemplateID = ""
+		return m, updateTemplateCmd(m.templatesPath, m.templates, t)
 	}
 	return m, nil
 }
 
+// templateFromInputs reads the create/edit template form.
+func templateFromInputs(inputs []textinput.Model) (template, error) {
+	label := strings.TrimSpace(inputs[0].Value())
+	text := strings.TrimSpace(inputs[1].Value())
+	emoji := strings.TrimSpace(inputs[2].Value())
+	duration, err := parseOptionalMinutes(inputs[3].Value())
+	if err != nil {
+		return template{}, fmt.Errorf("duration: %w", err)
+	}
+	until := strings.TrimSpace(inputs[4].Value())
+	selector, err := parseYesNo(inputs[5].Value())
+	if err != nil {
+		return template{}, fmt.Errorf("duration selector: %w", err)
+	}
+	group := strings.TrimSpace(inputs[6].Value())
+	key := strings.TrimSpace(inputs[7].Value())
+	then := strings.TrimSpace(inputs[8].Value())
+	presence, err := parsePresence(inputs[9].Value())
+	if err != nil {
+		return template{}, err
+	}
+	dnd, err := parseYesNo(inputs[10].Value())
+	if err != nil {
+		return template{}, fmt.Errorf("dnd: %w", err)
+	}
+	if dnd && duration == nil && until == "" && !selector {
+		return template{}, errors.New("dnd needs a duration, an until time or the duration selector")
+	}
+	if action, reserved := reservedDashboardKeys[key]; reserved && key != "" {
+		return template{}, fmt.Errorf("hotkey %q is reserved for %s", key, action)
+	}
+	if label == "" || text == "" || emoji == "" {
+		return template{}, errors.New("label, text, and emoji are required")
+	}
+	if err := validateStatusText(text); err != nil {
+		return template{}, err
+	}
+	t := template{
+		Label:               label,
+		Text:                text,
+		Emoji:               emoji,
+		DurationInMinutes:   duration,
+		UseDurationSelector: selector,
+		Group:               group,
+		Key:                 key,
+		Then:                then,
+		Presence:            presence,
+		DND:                 dnd,
+		Workspaces:          parseWorkspaceList(inputs[11].Value()),
+	}
+	if kind, ok := parseUntilMeeting(until); ok {
+		t.UntilMeeting = kind
+		return t, nil
+	}
+	t.UntilTime, t.UntilTimeByWeekday, t.UntilTimeHoliday, err = parseUntilSpec(until)
+	if err != nil {
+		return template{}, err
+	}
+	return t, nil
+}
+
 func (m model) submitSettingsForm() (tea.Model, tea.Cmd) {
 	if len(m.inputs) == 0 {
 		return m.withError(errors.New("no inputs to submit")), nil
@@ -420,8 +782,34 @@ func (m model) submitSettingsForm() (tea.Model, tea.Cmd) {
 	if token == "" {
 		return m.withError(errors.New("slack token is required")), nil
 	}
+	// An unchanged rotating token keeps its refresh token.
+	cred := m.cfg.primaryCredentials()
+	if token != cred.Token {
+		cred = credentials{Token: token}
+	}
 	m.state = viewDashboard
-	return m, saveConfigCmd(m.configPath, token, m.confirmDelete)
+	return m, saveConfigCmd(m.configPath, m.cfg, cred, m.confirmDelete)
+}
+
+// applyConfig switches to a saved config.json, rebuilding the Slack clients.
+func (m model) applyConfig(cfg config, path string) (model, tea.Cmd) {
+	m.cfg = cfg
+	m.confirmDelete = effectiveConfirmDelete(cfg)
+	m.configPath = path
+	m.offlineQueuePath = offlineQueuePathFor(path)
+	workspaces, err := buildWorkspaces(cfg, m.offlineQueuePath)
+	if err != nil {
+		return m.withError(err), nil
+	}
+	m.workspaces, m.client = workspaces, workspaces[0].client
+	m.statuses, m.workspaceErrs = map[string]statusInfo{}, map[string]error{}
+	m.ownWrites, m.lastChange = map[string]time.Time{}, nil
+	m.health = nil
+	cmds := []tea.Cmd{m.fetchStatusesCmd(), checkHealthCmd(cfg, m.workspaces)}
+	if !m.emoji.fresh(m.workspaces, time.Now()) {
+		cmds = append(cmds, fetchCustomEmojiCmd(m.emojiCachePath, m.emoji, m.workspaces))
+	}
+	return m, tea.Batch(cmds...)
 }
 
 // handleCalEvents is the calendar sync state machine. It is called after every poll.
@@ -437,7 +825,7 @@ func (m model) handleCalEvents(nowEvents []calEvent, now time.Time) (tea.Model,
 		ev := earliestStartEvent(nowEvents)
 		logCal("State A→Meeting: frühestes Event %q (%s) → Status sichern", ev.Subject, ev.StartTime.Local().Format("15:04"))
 		m.calSync.pendingEvent = &ev
-		return m, saveCurrentStatusCmd(m.client, m.calSyncCfg.StatePath)
+		return m, saveCurrentStatusCmd(m.calSyncTargets(), m.calSyncCfg.StatePath, m.calSyncCfg.SnoozeDuringMeetings)
 	}
 
 	// CASE B: Wir verfolgen gerade ein aktives Meeting.
@@ -449,5 +837,5 @@ func (m model) handleCalEvents(nowEvents []calEvent, now time.Time) (tea.Model,
 	}
 
 	logCal("State B2: Meeting %q beendet → Status wiederherstellen", m.calSync.ActiveEventID[:min(8, len(m.calSync.ActiveEventID))])
-	return m, restorePreviousStatusCmd(m.client, m.calSyncCfg.StatePath)
+	return m, restorePreviousStatusCmd(m.calSyncTargets(), m.calSyncCfg.StatePath, m.calSync.overridden)
 }
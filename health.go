@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/slack-go/slack"
+)
+
+// scopeCheck ties a user scope to the feature that needs it.
+type scopeCheck struct {
+	scope    string
+	feature  string
+	required bool
+}
+
+var scopeChecks = []scopeCheck{
+	{"users.profile:write", "set status", true},
+	{"users.profile:read", "read status", true},
+	{"users:read", "show presence", false},
+	{"users:write", "set presence", false},
+	{"dnd:read", "show DND state", false},
+	{"dnd:write", "snooze notifications", false},
+	{"emoji:read", "custom emoji", false},
+}
+
+// tokenHealth is what auth.test says about a workspace's token. Scopes is nil
+// when the API didn't report them (then nothing is assumed missing).
+type tokenHealth struct {
+	Workspace string
+	Team      string
+	User      string
+	Scopes    []string
+	Err       error
+}
+
+// missing returns the checks whose scope the token lacks.
+func (h tokenHealth) missing(required bool) []scopeCheck {
+	if h.Scopes == nil {
+		return nil
+	}
+	var out []scopeCheck
+	for _, c := range scopeChecks {
+		if c.required == required && !slices.Contains(h.Scopes, c.scope) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// checkToken calls auth.test itself: slack-go drops the X-OAuth-Scopes
+// header that lists the granted scopes.
+func checkToken(ctx context.Context, cfg config, token string) (tokenHealth, error) {
+	base := oauthConfig{}
+	if cfg.OAuth != nil {
+		base = *cfg.OAuth
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, base.apiURL()+"auth.test", strings.NewReader(url.Values{}.Encode()))
+	if err != nil {
+		return tokenHealth{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := slackHTTPClient.Do(req)
+	if err != nil {
+		return tokenHealth{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return tokenHealth{}, fmt.Errorf("auth.test: HTTP %d", res.StatusCode)
+	}
+	var body struct {
+		slack.SlackResponse
+		slack.AuthTestResponse
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return tokenHealth{}, fmt.Errorf("auth.test: %w", err)
+	}
+	if err := body.Err(); err != nil {
+		return tokenHealth{}, err
+	}
+	h := tokenHealth{Team: body.Team, User: body.User}
+	if header := res.Header.Get("X-OAuth-Scopes"); header != "" {
+		h.Scopes = []string{}
+		for _, s := range strings.Split(header, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				h.Scopes = append(h.Scopes, s)
+			}
+		}
+	}
+	return h, nil
+}
+
+// requireScopes rejects a token that can't set or read the status.
+func requireScopes(h tokenHealth) error {
+	var names []string
+	for _, c := range h.missing(true) {
+		names = append(names, c.scope)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return fmt.Errorf("token lacks the scope %s, add it to the Slack app and reinstall it", strings.Join(names, ", "))
+}
+
+// checkHealthCmd runs auth.test for every workspace.
+func checkHealthCmd(cfg config, workspaces []workspace) tea.Cmd {
+	return func() tea.Msg {
+		out := make([]tokenHealth, len(workspaces))
+		for i, ws := range workspaces {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			h, err := checkToken(ctx, cfg, ws.token)
+			cancel()
+			h.Workspace, h.Err = ws.name, err
+			out[i] = h
+		}
+		return healthMsg(out)
+	}
+}
+
+// healthError names the workspaces that can't set a status at all.
+func healthError(health []tokenHealth) error {
+	var errs []error
+	for _, h := range health {
+		ws := workspace{name: h.Workspace}
+		if h.Err != nil {
+			errs = append(errs, ws.wrap(fmt.Errorf("token: %w", h.Err)))
+		} else if err := requireScopes(h); err != nil {
+			errs = append(errs, ws.wrap(err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// renderHealth is the health panel of the settings view.
+func renderHealth(health []tokenHealth) string {
+	if health == nil {
+		return "Health: checking…"
+	}
+	var b strings.Builder
+	b.WriteString("Health")
+	for _, h := range health {
+		name := workspace{name: h.Workspace}.label()
+		if h.Err != nil {
+			fmt.Fprintf(&b, "\n  %s: ✗ %v", name, h.Err)
+			continue
+		}
+		fmt.Fprintf(&b, "\n  %s: %s as @%s", name, h.Team, h.User)
+		if h.Scopes == nil {
+			b.WriteString("\n    Scopes: not reported")
+			continue
+		}
+		fmt.Fprintf(&b, "\n    Scopes: %s", missing(strings.Join(h.Scopes, ", "), "none"))
+		for _, required := range []bool{true, false} {
+			checks := h.missing(required)
+			if len(checks) == 0 {
+				continue
+			}
+			parts := make([]string, len(checks))
+			for i, c := range checks {
+				parts[i] = fmt.Sprintf("%s (%s)", c.feature, c.scope)
+			}
+			label := "Unavailable"
+			if required {
+				label = "✗ Missing"
+			}
+			fmt.Fprintf(&b, "\n    %s: %s", label, strings.Join(parts, ", "))
+		}
+		if len(h.missing(true)) == 0 && len(h.missing(false)) == 0 {
+			b.WriteString("\n    ✓ all features available")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	ical "github.com/emersion/go-ical"
+)
+
+// Holidays are configured once at startup (and on settings save) and read from
+// list delegates and commands, so they live in a small package-level calendar.
+var (
+	holidaysMu sync.Mutex
+	holidays   holidayCalendar
+)
+
+// holidayCalendar combines built-in regional rules, holiday ICS files and
+// user-defined dates. Regional dates are computed per year on demand.
+type holidayCalendar struct {
+	region string
+	fixed  map[string]string // date → name, from config and ICS files
+	years  map[int]map[string]string
+}
+
+// germanStates are the accepted holidayRegion codes ("DE" = nationwide only).
+var germanStates = map[string]string{
+	"DE": "Deutschland", "BW": "Baden-Württemberg", "BY": "Bayern", "BE": "Berlin",
+	"BB": "Brandenburg", "HB": "Bremen", "HH": "Hamburg", "HE": "Hessen",
+	"MV": "Mecklenburg-Vorpommern", "NI": "Niedersachsen", "NW": "Nordrhein-Westfalen",
+	"RP": "Rheinland-Pfalz", "SL": "Saarland", "SN": "Sachsen", "ST": "Sachsen-Anhalt",
+	"SH": "Schleswig-Holstein", "TH": "Thüringen",
+}
+
+// configureHolidays replaces the holiday calendar from config.json: the
+// built-in rules for holidayRegion, the dates of holidayIcs files and the
+// explicit holidays list.
+func configureHolidays(cfg config) error {
+	cal := holidayCalendar{fixed: map[string]string{}, years: map[int]map[string]string{}}
+	var problems []string
+
+	if cfg.HolidayRegion != "" {
+		region := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(cfg.HolidayRegion)), "DE-")
+		if _, ok := germanStates[region]; ok {
+			cal.region = region
+		} else {
+			problems = append(problems, fmt.Sprintf("unknown holidayRegion %q (use DE or a state code like BY, NW)", cfg.HolidayRegion))
+		}
+	}
+	for _, path := range cfg.HolidayICS {
+		if err := cal.addICS(path); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	var bad []string
+	for _, d := range cfg.Holidays {
+		d = strings.TrimSpace(d)
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			bad = append(bad, d)
+			continue
+		}
+		cal.fixed[d] = "Feiertag"
+	}
+	if len(bad) > 0 {
+		problems = append(problems, fmt.Sprintf("invalid dates %s (use YYYY-MM-DD)", strings.Join(bad, ", ")))
+	}
+
+	holidaysMu.Lock()
+	holidays = cal
+	holidaysMu.Unlock()
+	if len(problems) > 0 {
+		return fmt.Errorf("holidays: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// addICS adds every all-day (or multi-day) event of a holiday ICS file.
+func (c *holidayCalendar) addICS(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	cal, err := ical.NewDecoder(f).Decode()
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, ev := range cal.Events() {
+		start, err := ev.DateTimeStart(time.Local)
+		if err != nil {
+			continue
+		}
+		end, err := ev.DateTimeEnd(time.Local)
+		if err != nil || !end.After(start) {
+			end = start.AddDate(0, 0, 1)
+		}
+		name, _ := ev.Props.Text(ical.PropSummary)
+		for d := startOfDay(start); d.Before(end); d = d.AddDate(0, 0, 1) {
+			c.fixed[d.Format("2006-01-02")] = missing(name, "Feiertag")
+		}
+	}
+	return nil
+}
+
+// name returns the holiday name for day, or "".
+func (c *holidayCalendar) name(day time.Time) string {
+	key := day.Format("2006-01-02")
+	if n, ok := c.fixed[key]; ok {
+		return n
+	}
+	if c.region == "" {
+		return ""
+	}
+	return c.years[day.Year()][key]
+}
+
+// ensureYear computes the regional holidays of year once.
+func (c *holidayCalendar) ensureYear(year int) {
+	if c.region == "" {
+		return
+	}
+	if _, ok := c.years[year]; !ok {
+		c.years[year] = germanHolidays(year, c.region)
+	}
+}
+
+// isHoliday reports whether day is a public or configured holiday.
+func isHoliday(day time.Time) bool {
+	return holidayName(day) != ""
+}
+
+// holidayName returns the name of the holiday on day, or "".
+func holidayName(day time.Time) string {
+	holidaysMu.Lock()
+	defer holidaysMu.Unlock()
+	holidays.ensureYear(day.Year())
+	return holidays.name(day)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// ── German public holidays ───────────────────────────────────────────────────
+
+// easterSunday computes Easter Sunday (Gregorian calendar, anonymous algorithm).
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
+}
+
+// germanHolidays lists the public holidays of year in the federal state
+// region ("DE" for the nationwide ones only).
+func germanHolidays(year int, region string) map[string]string {
+	out := map[string]string{}
+	add := func(t time.Time, name string, states ...string) {
+		if len(states) > 0 {
+			found := false
+			for _, s := range states {
+				found = found || s == region
+			}
+			if !found {
+				return
+			}
+		}
+		out[t.Format("2006-01-02")] = name
+	}
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	}
+	easter := easterSunday(year)
+
+	add(date(time.January, 1), "Neujahr")
+	add(date(time.January, 6), "Heilige Drei Könige", "BW", "BY", "ST")
+	if year >= 2019 {
+		states := []string{"BE"}
+		if year >= 2023 {
+			states = append(states, "MV")
+		}
+		add(date(time.March, 8), "Internationaler Frauentag", states...)
+	}
+	add(easter.AddDate(0, 0, -2), "Karfreitag")
+	add(easter, "Ostersonntag", "BB")
+	add(easter.AddDate(0, 0, 1), "Ostermontag")
+	add(date(time.May, 1), "Tag der Arbeit")
+	add(easter.AddDate(0, 0, 39), "Christi Himmelfahrt")
+	add(easter.AddDate(0, 0, 49), "Pfingstsonntag", "BB")
+	add(easter.AddDate(0, 0, 50), "Pfingstmontag")
+	add(easter.AddDate(0, 0, 60), "Fronleichnam", "BW", "BY", "HE", "NW", "RP", "SL")
+	add(date(time.August, 15), "Mariä Himmelfahrt", "SL")
+	if year >= 2019 {
+		add(date(time.September, 20), "Weltkindertag", "TH")
+	}
+	add(date(time.October, 3), "Tag der Deutschen Einheit")
+	// 2017 (500 years of the Reformation) it was a holiday in every state.
+	switch {
+	case year == 2017:
+		add(date(time.October, 31), "Reformationstag")
+	case year >= 2018:
+		add(date(time.October, 31), "Reformationstag", "BB", "MV", "SN", "ST", "TH", "HB", "HH", "NI", "SH")
+	default:
+		add(date(time.October, 31), "Reformationstag", "BB", "MV", "SN", "ST", "TH")
+	}
+	add(date(time.November, 1), "Allerheiligen", "BW", "BY", "NW", "RP", "SL")
+	// Buß- und Bettag: the Wednesday before 23 November.
+	bb := date(time.November, 22)
+	for bb.Weekday() != time.Wednesday {
+		bb = bb.AddDate(0, 0, -1)
+	}
+	add(bb, "Buß- und Bettag", "SN")
+	add(date(time.December, 25), "1. Weihnachtstag")
+	add(date(time.December, 26), "2. Weihnachtstag")
+	return out
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEasterSunday(t *testing.T) {
+	tests := map[int]string{
+		2000: "2000-04-23",
+		2008: "2008-03-23",
+		2011: "2011-04-24",
+		2019: "2019-04-21",
+		2024: "2024-03-31",
+		2025: "2025-04-20",
+		2026: "2026-04-05",
+		2038: "2038-04-25",
+	}
+	for year, want := range tests {
+		if got := easterSunday(year).Format("2006-01-02"); got != want {
+			t.Errorf("easterSunday(%d) = %s, want %s", year, got, want)
+		}
+	}
+}
+
+func TestGermanHolidays(t *testing.T) {
+	tests := []struct {
+		region, date, want string
+	}{
+		{"DE", "2026-05-14", "Christi Himmelfahrt"},
+		{"DE", "2026-06-04", ""},
+		{"BY", "2026-06-04", "Fronleichnam"},
+		{"NW", "2026-06-04", "Fronleichnam"},
+		{"BE", "2026-06-04", ""},
+		{"BE", "2026-03-08", "Internationaler Frauentag"},
+		{"MV", "2022-03-08", ""},
+		{"MV", "2023-03-08", "Internationaler Frauentag"},
+		{"BB", "2026-04-05", "Ostersonntag"},
+		{"SN", "2026-11-18", "Buß- und Bettag"},
+		{"TH", "2026-09-20", "Weltkindertag"},
+		{"HH", "2016-10-31", ""},
+		{"HH", "2018-10-31", "Reformationstag"},
+		{"BY", "2018-10-31", ""},
+		// 2017 was a nationwide exception.
+		{"BY", "2017-10-31", "Reformationstag"},
+		{"DE", "2017-10-31", "Reformationstag"},
+	}
+	for _, tt := range tests {
+		day, err := time.Parse("2006-01-02", tt.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := germanHolidays(day.Year(), tt.region)[tt.date]; got != tt.want {
+			t.Errorf("%s %s = %q, want %q", tt.region, tt.date, got, tt.want)
+		}
+	}
+}
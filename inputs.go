@@ -5,13 +5,14 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 )
 
 func buildStatusInputs(text, emoji string) []textinput.Model {
-	fields := []string{"Status text", "Emoji (:coffee:)", "Duration (minutes, optional)", "Until time (HH:MM, optional)"}
-	values := []string{text, emoji, "", ""}
+	fields := []string{"Status text", "Emoji (:coffee:)", "Duration (45, 90m, 1h30, 2d, optional)", "Until (14:00, friday, next monday 9am, eod, eow, nextMeeting, optional)", "Presence (auto or away, optional)"}
+	values := []string{text, emoji, "", "", ""}
 	inputs := make([]textinput.Model, len(fields))
 	for i := range inputs {
 		ti := textinput.New()
@@ -26,13 +27,85 @@ func buildStatusInputs(text, emoji string) []textinput.Model {
 	return inputs
 }
 
-func buildTemplateInputs() []textinput.Model {
-	fields := []string{"Template name", "Status text", "Emoji (:house:)", "Duration (minutes, optional)", "Until time (HH:MM, optional)"}
+// buildSetLaterInputs builds the set-later form, prefilled when editing q.
+func buildSetLaterInputs(q *queuedStatus) []textinput.Model {
+	fields := []string{"When (tomorrow 10:00, friday 9am, in 2h)", "Template (label, optional)", "Status text (without template)", "Emoji (without template)", "Until (relative to start: 11:30, 90m, optional)", "If missed: apply or skip (default apply)"}
+	values := make([]string, len(fields))
+	if q != nil {
+		values = []string{time.Unix(q.At, 0).Format("2006-01-02 15:04"), missing(q.Label, q.Template), q.Text, q.Emoji, "", q.Late}
+		if q.Until > 0 {
+			values[4] = time.Unix(q.Until, 0).Format("2006-01-02 15:04")
+		}
+	}
+	inputs := make([]textinput.Model, len(fields))
+	for i := range inputs {
+		ti := textinput.New()
+		ti.Placeholder = fields[i]
+		ti.CharLimit = 128
+		ti.SetValue(values[i])
+		if i == 0 {
+			ti.Focus()
+		}
+		inputs[i] = ti
+	}
+	return inputs
+}
+
+// buildVacationInputs builds the vacation form, prefilled from a planned one.
+func buildVacationInputs(v *vacation) []textinput.Model {
+	fields := []string{"First day (2026-12-24, 24.12., monday; default today)", "Last day", "Back on (optional, default next workday start)", "Delegate (optional, e.g. @max)", "Status text (default " + defaultVacationText + ")", "Emoji (default " + defaultVacationEmoji + ")", "Template when back (optional, empty clears the status)", "Do not disturb during vacation (y/n)"}
+	values := make([]string, len(fields))
+	if v != nil {
+		dnd := "n"
+		if v.DND {
+			dnd = "y"
+		}
+		values = []string{
+			time.Unix(v.Start, 0).Format("2006-01-02"),
+			v.LastDay,
+			time.Unix(v.Return, 0).Format("2006-01-02"),
+			v.Delegate, v.Text, v.Emoji, missing(v.ReturnLabel, v.ReturnTemplate), dnd,
+		}
+	}
+	inputs := make([]textinput.Model, len(fields))
+	for i := range inputs {
+		ti := textinput.New()
+		ti.Placeholder = fields[i]
+		ti.CharLimit = 128
+		ti.SetValue(values[i])
+		if i == 0 {
+			ti.Focus()
+		}
+		inputs[i] = ti
+	}
+	return inputs
+}
+
+func buildTemplateInputs(t template) []textinput.Model {
+	fields := []string{"Template name", "Status text", "Emoji (:house:)", "Duration (45, 90m, 1h30, 2d, optional)", "Until (16:30 or eod; fri=12:30; holiday=HH:MM, nextMeeting or meetingEnd, optional)", "Ask for duration on apply (y/n)", "Group (optional)", "Hotkey (optional, e.g. 1 or l)", "Then (template name or previous, optional)", "Presence (auto or away, optional)", "Snooze notifications for the status duration (y/n)", "Workspaces (comma separated, empty = all)"}
+	duration := ""
+	if t.DurationInMinutes != nil {
+		duration = strconv.Itoa(*t.DurationInMinutes)
+	}
+	until := formatUntilSpec(t)
+	if t.UntilMeeting != "" {
+		until = t.UntilMeeting
+	}
+	selector := ""
+	if t.UseDurationSelector {
+		selector = "y"
+	}
+	dnd := ""
+	if t.DND {
+		dnd = "y"
+	}
+	values := []string{t.Label, t.Text, t.Emoji, duration, until, selector, t.Group, t.Key, t.Then, t.Presence, dnd, strings.Join(t.Workspaces, ", ")}
 	inputs := make([]textinput.Model, len(fields))
 	for i := range inputs {
 		ti := textinput.New()
 		ti.Placeholder = fields[i]
 		ti.CharLimit = 128
+		ti.SetValue(values[i])
 		if i == 0 {
 			ti.Focus()
 		}
@@ -41,11 +114,30 @@ func buildTemplateInputs() []textinput.Model {
 	return inputs
 }
 
+func buildSnoozeInput() []textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "Snooze for (30m, 2h, until 14:00; default 60m)"
+	ti.CharLimit = 64
+	ti.Focus()
+	return []textinput.Model{ti}
+}
+
+func buildGroupInput(group string) []textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "Group (empty = no group)"
+	ti.CharLimit = 64
+	ti.SetValue(group)
+	ti.Focus()
+	return []textinput.Model{ti}
+}
+
 func buildSettingsInputs(token string) []textinput.Model {
 	inputs := make([]textinput.Model, 1)
 	ti := textinput.New()
 	ti.Placeholder = "Slack token"
-	ti.CharLimit = 128
+	ti.CharLimit = 256
+	ti.EchoMode = textinput.EchoPassword
+	ti.EchoCharacter = '•'
 	ti.SetValue(token)
 	ti.Focus()
 	inputs[0] = ti
@@ -54,6 +146,9 @@ func buildSettingsInputs(token string) []textinput.Model {
 
 func buildDurationValueInput(unit durationUnit) []textinput.Model {
 	fields := []string{fmt.Sprintf("Anzahl %s", durationUnitLabel(unit))}
+	if unit == durationCustom {
+		fields = []string{"z.B. 90m, 1h30, 2d, bis 14:00, freitag, next monday 9am, eod, feierabend, 3 arbeitstage"}
+	}
 	inputs := make([]textinput.Model, len(fields))
 	for i := range inputs {
 		ti := textinput.New()
@@ -67,16 +162,32 @@ func buildDurationValueInput(unit durationUnit) []textinput.Model {
 	return inputs
 }
 
-func parseOptionalInt(v string) (*int, error) {
+// parseOptionalMinutes parses an optional duration field ("45", "90m", "1h30",
+// "2d") into whole minutes.
+func parseOptionalMinutes(v string) (*int, error) {
 	v = strings.TrimSpace(v)
 	if v == "" {
 		return nil, nil
 	}
-	i, err := strconv.Atoi(v)
+	d, err := parseDurationExpr(v)
 	if err != nil {
 		return nil, err
 	}
-	return &i, nil
+	minutes := int(d / time.Minute)
+	if minutes <= 0 {
+		return nil, errors.New("duration must be at least one minute")
+	}
+	return &minutes, nil
+}
+
+func parseYesNo(v string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", "n", "no", "nein", "false":
+		return false, nil
+	case "y", "yes", "j", "ja", "true":
+		return true, nil
+	}
+	return false, fmt.Errorf("expected y or n, got %q", v)
 }
 
 func parsePositiveInt(v string) (int, error) {
@@ -102,6 +213,10 @@ func durationUnitLabel(unit durationUnit) string {
 		return "Stunden"
 	case durationMinutes:
 		return "Minuten"
+	case durationWorkdays:
+		return "Arbeitstage"
+	case durationCustom:
+		return "freie Eingabe"
 	default:
 		return "Minuten"
 	}
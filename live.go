@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+const (
+	defaultLivePollSeconds = 60
+
+	// Likely causes of a status change.
+	sourceApp       = "set here"
+	sourceExpiry    = "expired"
+	sourceElsewhere = "changed elsewhere"
+
+	// ownWriteWindow is how long a change after one of our writes counts as
+	// ours; liveChangeShown how long the status card mentions a change.
+	ownWriteWindow  = 2 * time.Minute
+	liveChangeShown = 15 * time.Minute
+)
+
+// livePollInterval is the background refresh interval, zero when off.
+func livePollInterval(cfg config) time.Duration {
+	seconds := defaultLivePollSeconds
+	if cfg.LiveStatus != nil && cfg.LiveStatus.PollSeconds != 0 {
+		seconds = cfg.LiveStatus.PollSeconds
+	}
+	if seconds < 0 {
+		return 0
+	}
+	return time.Duration(max(seconds, 10)) * time.Second
+}
+
+func livePollCmd(interval time.Duration) tea.Cmd {
+	if interval <= 0 {
+		return nil
+	}
+	return tea.Tick(interval, func(time.Time) tea.Msg { return livePollMsg{} })
+}
+
+// fetchLiveStatusesCmd refreshes every workspace in the background; errors
+// are dropped, the next poll tries again.
+func (m model) fetchLiveStatusesCmd() tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(m.workspaces))
+	for _, ws := range m.workspaces {
+		fetch := fetchStatusCmd(ws)
+		cmds = append(cmds, func() tea.Msg {
+			if msg, ok := fetch().(statusMsg); ok {
+				return liveStatusMsg(msg)
+			}
+			return nil
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+func sameStatus(a, b statusInfo) bool {
+	return a.Text == b.Text && a.Emoji == b.Emoji && a.ExpirationUnix == b.ExpirationUnix
+}
+
+// markOwnWrite notes that we just set the status of ws, so the change the
+// next fetch sees is not reported.
+func (m model) markOwnWrite(names ...string) {
+	now := time.Now()
+	for _, name := range names {
+		m.ownWrites[name] = now
+	}
+}
+
+// noteStatus compares a fetched status with the previous one of its
+// workspace and records what changed it. Changes made elsewhere during a
+// cal-sync meeting keep cal-sync from restoring over them.
+func (m model) noteStatus(info statusInfo, now time.Time) model {
+	prev, known := m.statuses[info.Workspace]
+	m.statuses[info.Workspace] = info
+	if !known || sameStatus(prev, info) {
+		return m
+	}
+	change := statusChange{Workspace: info.Workspace, Old: prev, New: info, At: now}
+	switch wrote, ok := m.ownWrites[info.Workspace]; {
+	case ok && now.Sub(wrote) < ownWriteWindow:
+		change.Source = sourceApp
+	case info.Text == "" && info.Emoji == "" && prev.ExpirationUnix > 0 && prev.ExpirationUnix <= now.Unix()+60:
+		change.Source = sourceExpiry
+	default:
+		change.Source = sourceElsewhere
+	}
+	delete(m.ownWrites, info.Workspace)
+	if change.Source == sourceApp {
+		return m
+	}
+	m.lastChange = &change
+	ws, ok := m.workspaceNamed(info.Workspace)
+	if change.Source == sourceElsewhere && ok && ws.calSync && m.calSync.ActiveEventID != "" {
+		if m.calSync.overridden == nil {
+			m.calSync.overridden = map[string]bool{}
+		}
+		m.calSync.overridden[ws.name] = true
+		logCal("%sStatus während des Meetings geändert → wird nicht wiederhergestellt", wsPrefix(ws))
+	}
+	return m
+}
+
+// handleLiveStatus shows a background refresh without touching the message
+// line.
+func (m model) handleLiveStatus(info statusInfo) model {
+	m = m.noteStatus(info, time.Now())
+	delete(m.workspaceErrs, info.Workspace)
+	if len(m.workspaces) > 0 && info.Workspace == m.workspaces[0].name {
+		info.Presence = m.status.Presence
+		m.status = info
+	}
+	return m
+}
+
+// describe is the notification line of a change.
+func (c statusChange) describe(multi bool) string {
+	status := func(s statusInfo) string {
+		return missing(renderEmoji(strings.TrimSpace(s.Emoji+" "+s.Text)), "-")
+	}
+	line := fmt.Sprintf("↻ %s Status %s", c.At.Local().Format("15:04"), c.Source)
+	if multi {
+		line += " in " + workspace{name: c.Workspace}.label()
+	}
+	if c.Source == sourceExpiry {
+		return line + ": " + status(c.Old)
+	}
+	return line + ": " + status(c.Old) + " → " + status(c.New)
+}
+
+// liveChangeLine is the faint notification on the status card.
+func liveChangeLine(c *statusChange, multi bool, now time.Time) string {
+	if c == nil || now.Sub(c.At) > liveChangeShown {
+		return ""
+	}
+	return lipgloss.NewStyle().Faint(true).Render(c.describe(multi))
+}
+
+// ── Socket Mode ──────────────────────────────────────────────────────────────
+
+// socketModeCmd listens for user_change events of the user in all
+// workspaces and asks the model to refresh. It runs until the connection
+// fails for good.
+func socketModeCmd(appToken string, cfg config, workspaces []workspace) tea.Cmd {
+	return func() tea.Msg {
+		if len(workspaces) == 0 {
+			return nil
+		}
+		self := map[string]bool{}
+		for _, ws := range workspaces {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			id, err := selfUserID(ctx, ws.client)
+			cancel()
+			if err != nil {
+				return errMsg{fmt.Errorf("socket mode: %w", ws.wrap(err))}
+			}
+			self[id] = true
+		}
+		api := slack.New(workspaces[0].token, append(clientOptions(cfg), slack.OptionAppLevelToken(appToken))...)
+		sm := socketmode.New(api)
+		go func() {
+			for evt := range sm.Events {
+				if evt.Type != socketmode.EventTypeEventsAPI {
+					continue
+				}
+				if evt.Request != nil {
+					sm.Ack(*evt.Request)
+				}
+				ev, ok := evt.Data.(slackevents.EventsAPIEvent)
+				if !ok {
+					continue
+				}
+				if uc, ok := ev.InnerEvent.Data.(*slackevents.UserChangeEvent); ok && self[uc.User.ID] && teaProgram != nil {
+					teaProgram.Send(liveEventMsg{})
+				}
+			}
+		}()
+		err := sm.RunContext(context.Background())
+		if err == nil {
+			err = errors.New("connection closed")
+		}
+		return errMsg{fmt.Errorf("socket mode: %w", err)}
+	}
+}
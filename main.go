@@ -12,6 +12,9 @@ import (
 var teaProgram *tea.Program
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:], os.Stdout, os.Stderr))
+	}
 	m := initialModel()
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	teaProgram = p
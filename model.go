@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -35,6 +36,52 @@ type model struct {
 	calSyncEnabled bool
 	calSync        calSyncState
 	calSyncCfgPath string
+	// Template editing: id of the template open in viewEditTemplate
+	editingTemplateID string
+	// Template list: collapsed group names and the template to select after reload
+	collapsedGroups  map[string]bool
+	selectTemplateID string
+	// Follow-up statuses (then-chains) and their persisted queue
+	followUps        []followUp
+	followUpsPath    string
+	followUpChecking bool
+	// Recurring schedule (schedule.json)
+	schedule scheduleState
+	// One-off future statuses (status-queue.json)
+	queue          []queuedStatus
+	queuePath      string
+	queueCursor    int
+	editingQueueID string
+	// Vacation planner (vacation.json)
+	vacation         *vacation
+	vacationPath     string
+	vacationChecking bool
+	// Do Not Disturb (snooze) state
+	dnd dndState
+	// Slack workspaces; client is the first (primary) one's
+	workspaces    []workspace
+	statuses      map[string]statusInfo
+	workspaceErrs map[string]error
+	// Browser login in progress and rotating-token refresh
+	loginURL         string
+	refreshingTokens bool
+	refresh          refreshState
+	// Token health per workspace (auth.test); nil until checked
+	health []tokenHealth
+	// Status writes waiting for Slack to be reachable (offline-queue.json)
+	offlineQueue     []pendingWrite
+	offlineQueuePath string
+	replayingOffline bool
+	// Background refresh: when we last wrote each workspace's status and
+	// the last change seen that we didn't make
+	ownWrites  map[string]time.Time
+	lastChange *statusChange
+	// Custom emoji (emoji-cache.json), recently picked ones and the picker
+	emoji           emojiCache
+	emojiCachePath  string
+	recentEmoji     []string
+	emojiRecentPath string
+	picker          *emojiPicker
 }
 
 func initialModel() model {
@@ -42,17 +89,42 @@ func initialModel() model {
 	tmplPath, tmplErr := ensureTemplatesFile()
 
 	var client *slack.Client
+	var workspaces []workspace
 	var status statusInfo
 	var loadErr error
 	var cfg config
+	message := "Tab to switch, Enter to use, ? for help"
 
 	if cfgErr == nil {
+		// Configs from before token stores hold the token in plain text.
+		if raw, err := readConfig(cfgPath); err == nil {
+			if note, err := migrateSecrets(cfgPath, raw); err != nil {
+				loadErr = err
+			} else if note != "" {
+				message = note
+			}
+		}
 		loaded, err := loadConfig(cfgPath)
-		if err != nil {
+		if errors.Is(err, errNoToken) {
+			cfg = loaded
+			loadErr = fmt.Errorf("%w (press s and Ctrl+O to log in)", err)
+		} else if err != nil && loadErr == nil {
 			loadErr = err
-		} else {
+		} else if err == nil {
 			cfg = loaded
-			client = slack.New(cfg.SlackToken)
+			workspaces, err = buildWorkspaces(cfg, offlineQueuePathFor(cfgPath))
+			if err != nil {
+				loadErr = err
+			} else {
+				client = workspaces[0].client
+			}
+			configureEmoji(cfg)
+			if err := configureHolidays(cfg); err != nil && loadErr == nil {
+				loadErr = err
+			}
+			if err := configureWorkingHours(cfg.WorkingHours); err != nil && loadErr == nil {
+				loadErr = err
+			}
 		}
 	} else {
 		loadErr = cfgErr
@@ -68,9 +140,10 @@ func initialModel() model {
 		tmplPath = ""
 	}
 
-	delegate := newTemplateDelegate()
+	delegate := newTemplateListDelegate()
 	ls := list.New([]list.Item{}, delegate, 42, 16)
 	ls.Title = "Status Templates"
+	ls.KeyMap = dashboardListKeys()
 	ls.SetShowStatusBar(false)
 	ls.SetShowHelp(false)
 	ls.DisableQuitKeybindings()
@@ -107,23 +180,83 @@ func initialModel() model {
 		}
 	}
 
+	followUpsPath := followUpsPathFor(cfgPath)
+	followUps, err := loadFollowUps(followUpsPath)
+	if err != nil && loadErr == nil {
+		loadErr = err
+	}
+
+	schedule, err := loadSchedule(time.Now())
+	if err != nil && loadErr == nil {
+		loadErr = err
+	}
+
+	queuePath := queuePathFor(cfgPath)
+	queue, err := loadQueue(queuePath)
+	if err != nil && loadErr == nil {
+		loadErr = err
+	}
+
+	vacationPath := vacationPathFor(cfgPath)
+	vac, err := loadVacation(vacationPath)
+	if err != nil && loadErr == nil {
+		loadErr = err
+	}
+
+	emojiCachePath, emojiRecentPath := emojiCachePathFor(cfgPath), emojiRecentPathFor(cfgPath)
+	emoji, err := loadEmojiCache(emojiCachePath)
+	if err != nil && loadErr == nil {
+		loadErr = err
+	}
+	recentEmoji, err := loadRecentEmoji(emojiRecentPath)
+	if err != nil && loadErr == nil {
+		loadErr = err
+	}
+
+	offlineQueuePath := offlineQueuePathFor(cfgPath)
+	offlineQueue, err := loadOfflineQueue(offlineQueuePath)
+	if err != nil && loadErr == nil {
+		loadErr = err
+	}
+
 	return model{
+		vacation:       vac,
+		vacationPath:   vacationPath,
+		queue:          queue,
+		queuePath:      queuePath,
+		schedule:       schedule,
+		followUps:      followUps,
+		followUpsPath:  followUpsPath,
 		client:         client,
+		workspaces:     workspaces,
+		statuses:       map[string]statusInfo{},
+		workspaceErrs:  map[string]error{},
+		ownWrites:      map[string]time.Time{},
 		status:         status,
 		cfg:            cfg,
 		confirmDelete:  effectiveConfirmDelete(cfg),
 		templates:      []template{},
 		templateList:   ls,
-		durationList:   newDurationList(42, 16),
+		durationList:   newDurationList(42, 16, calEnabled),
 		templatesPath:  tmplPath,
 		configPath:     cfgPath,
 		state:          viewDashboard,
-		message:        "Tab to switch, Enter to use, ? for help",
+		message:        message,
 		err:            loadErr,
 		calSyncCfg:     calCfg,
 		calSyncEnabled: calEnabled,
 		calSyncCfgPath: calSyncCfgPath,
 		calSync:        calSync,
+		// Init renews a rotating token that expired while the app was closed.
+		refreshingTokens: refreshTokensCmd(cfgPath, cfg, time.Now(), nil) != nil,
+		offlineQueue:     offlineQueue,
+		offlineQueuePath: offlineQueuePath,
+		emoji:            emoji,
+		emojiCachePath:   emojiCachePath,
+		recentEmoji:      recentEmoji,
+		emojiRecentPath:  emojiRecentPath,
+		// Init replays writes that were still queued when the app was closed.
+		replayingOffline: len(offlineQueue) > 0 && len(workspaces) > 0,
 	}
 }
 
@@ -144,23 +277,60 @@ func (m model) enterEditForm() model {
 func (m model) enterCreateTemplateForm() model {
 	m.state = viewCreateTemplate
 	m.message = "Create a reusable template"
-	m.inputs = buildTemplateInputs()
+	m.inputs = buildTemplateInputs(template{})
+	m.focusIndex = 0
+	return m
+}
+
+func (m model) enterEditTemplateForm(t template) model {
+	m.state = viewEditTemplate
+	m.message = "Edit template"
+	m.editingTemplateID = t.ID
+	m.inputs = buildTemplateInputs(t)
+	m.focusIndex = 0
+	return m
+}
+
+func (m model) enterMoveTemplateGroupForm(t template) model {
+	m.state = viewMoveTemplateGroup
+	m.message = "Move template to group"
+	m.editingTemplateID = t.ID
+	m.inputs = buildGroupInput(t.Group)
+	m.focusIndex = 0
 	return m
 }
 
-func (m model) enterSettings() model {
+// refreshTemplateList rebuilds the list items from m.templates, keeping the
+// cursor on selectTemplateID when set.
+func (m model) refreshTemplateList() model {
+	items := templateListItems(m.templates, m.collapsedGroups)
+	m.templateList.SetItems(items)
+	if m.selectTemplateID != "" {
+		for i, it := range items {
+			if t, ok := it.(templateItem); ok && t.ID == m.selectTemplateID {
+				m.templateList.Select(i)
+				break
+			}
+		}
+		m.selectTemplateID = ""
+	}
+	return m
+}
+
+func (m model) enterSettings() (model, tea.Cmd) {
 	m.state = viewSettings
+	m.health = nil
 	m.message = "Update settings"
-	m.inputs = buildSettingsInputs(m.cfg.SlackToken)
+	m.inputs = buildSettingsInputs(m.cfg.primaryCredentials().Token)
 	m.focusIndex = 0
-	return m
+	return m, checkHealthCmd(m.cfg, m.workspaces)
 }
 
 func (m model) enterDurationSelector(t template) model {
 	w, h := panelSize(m.width, m.height)
 	m.state = viewDurationSelector
 	m.pendingTemplate = &t
-	m.durationList = newDurationList(w, h)
+	m.durationList = newDurationList(w, h, m.calSyncEnabled)
 	m.message = "Dauer waehlen"
 	m.inputs = nil
 	m.focusIndex = 0
@@ -0,0 +1,424 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/slack-go/slack"
+)
+
+const (
+	defaultAuthorizeURL = "https://slack.com/oauth/v2/authorize"
+	defaultRedirectURL  = "http://localhost:8734/callback"
+	// loginTimeout is how long the callback server waits for the browser.
+	loginTimeout = 5 * time.Minute
+	// tokenRefreshMargin refreshes rotating tokens before they expire.
+	tokenRefreshMargin = 10 * time.Minute
+)
+
+// defaultUserScopes cover status, presence, DND and custom emoji.
+var defaultUserScopes = []string{
+	"users.profile:read", "users.profile:write",
+	"users:read", "users:write",
+	"dnd:read", "dnd:write",
+	"emoji:read",
+}
+
+func (oc oauthConfig) authorizeURL() string {
+	return missing(oc.AuthorizeURL, defaultAuthorizeURL)
+}
+
+func (oc oauthConfig) redirectURL() string {
+	return missing(oc.RedirectURL, defaultRedirectURL)
+}
+
+// apiURL is the Web API base, always with a trailing slash like slack.APIURL.
+func (oc oauthConfig) apiURL() string {
+	u := missing(oc.APIURL, slack.APIURL)
+	if !strings.HasSuffix(u, "/") {
+		u += "/"
+	}
+	return u
+}
+
+func (oc oauthConfig) scopes() []string {
+	if len(oc.UserScopes) > 0 {
+		return oc.UserScopes
+	}
+	return defaultUserScopes
+}
+
+// oauthFor returns the login settings, or an error naming what is missing.
+func oauthFor(cfg config) (oauthConfig, error) {
+	if cfg.OAuth == nil || cfg.OAuth.ClientID == "" || cfg.OAuth.ClientSecret == "" {
+		return oauthConfig{}, errors.New("login needs oauth.clientId and oauth.clientSecret in config.json")
+	}
+	return *cfg.OAuth, nil
+}
+
+// clientOptions sends the Slack clients through the retrying HTTP client and
+// points them at oauth.apiUrl when it is set.
+func clientOptions(cfg config) []slack.Option {
+	opts := []slack.Option{slack.OptionHTTPClient(slackHTTPClient)}
+	if cfg.OAuth == nil || cfg.OAuth.APIURL == "" {
+		return opts
+	}
+	return append(opts, slack.OptionAPIURL(cfg.OAuth.apiURL()))
+}
+
+// ── Login ────────────────────────────────────────────────────────────────────
+
+// loginSession is a running browser login: the callback server listens on
+// the redirect URL until Slack sends the browser back with a code.
+type loginSession struct {
+	oc       oauthConfig
+	URL      string
+	redirect string
+	state    string
+	server   *http.Server
+	result   chan loginCallback
+}
+
+type loginCallback struct {
+	code string
+	err  error
+}
+
+// startLogin starts the callback server and builds the authorize URL.
+func startLogin(oc oauthConfig) (*loginSession, error) {
+	redirect, err := url.Parse(oc.redirectURL())
+	if err != nil || redirect.Host == "" {
+		return nil, fmt.Errorf("oauth.redirectUrl %q is not a URL", oc.redirectURL())
+	}
+	ln, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return nil, fmt.Errorf("callback server: %w", err)
+	}
+	s := &loginSession{
+		oc:       oc,
+		redirect: redirect.String(),
+		state:    newTemplateID() + newTemplateID(),
+		result:   make(chan loginCallback, 1),
+	}
+	q := url.Values{
+		"client_id":    {oc.ClientID},
+		"user_scope":   {strings.Join(oc.scopes(), ",")},
+		"redirect_uri": {s.redirect},
+		"state":        {s.state},
+	}
+	s.URL = oc.authorizeURL() + "?" + q.Encode()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(missing(redirect.Path, "/"), s.handleCallback)
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() { _ = s.server.Serve(ln) }()
+	return s, nil
+}
+
+func (s *loginSession) handleCallback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var cb loginCallback
+	switch {
+	case q.Get("state") != s.state:
+		http.Error(w, "Login failed: state mismatch. Start the login again.", http.StatusBadRequest)
+		return
+	case q.Get("error") != "":
+		cb.err = fmt.Errorf("slack: %s", q.Get("error"))
+	case q.Get("code") == "":
+		cb.err = errors.New("slack sent no code")
+	default:
+		cb.code = q.Get("code")
+	}
+	msg := "Logged in, you can close this tab and go back to the terminal."
+	if cb.err != nil {
+		msg = "Login failed: " + cb.err.Error()
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<!doctype html><title>%s</title><p>%s</p>", appName, html.EscapeString(msg))
+	select {
+	case s.result <- cb:
+	default:
+	}
+}
+
+// wait blocks until the browser comes back, exchanges the code and stops the
+// callback server. It returns the credentials and the team name.
+func (s *loginSession) wait(ctx context.Context) (credentials, string, error) {
+	defer s.server.Close()
+	var cb loginCallback
+	select {
+	case cb = <-s.result:
+	case <-ctx.Done():
+		return credentials{}, "", errors.New("no answer from the browser, login timed out")
+	}
+	if cb.err != nil {
+		return credentials{}, "", cb.err
+	}
+	ctx, cancel := context.WithTimeout(ctx, slackCallTimeout)
+	defer cancel()
+	resp, err := oauthAccess(ctx, s.oc, url.Values{"code": {cb.code}, "redirect_uri": {s.redirect}})
+	if err != nil {
+		return credentials{}, "", fmt.Errorf("code exchange: %w", err)
+	}
+	cred, err := credentialsFrom(resp, time.Now())
+	return cred, resp.Team.Name, err
+}
+
+// oauthAccess calls oauth.v2.access through the retrying client. slack-go's
+// helpers always talk to slack.com, this one honours oauth.apiUrl.
+func oauthAccess(ctx context.Context, oc oauthConfig, values url.Values) (*slack.OAuthV2Response, error) {
+	values.Set("client_id", oc.ClientID)
+	values.Set("client_secret", oc.ClientSecret)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, oc.apiURL()+"oauth.v2.access", strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := slackHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oauth.v2.access: HTTP %d", res.StatusCode)
+	}
+	var resp slack.OAuthV2Response
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("oauth.v2.access: %w", err)
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// credentialsFrom picks the user token: the code exchange returns it under
+// authed_user, a refresh at the top level.
+func credentialsFrom(resp *slack.OAuthV2Response, now time.Time) (credentials, error) {
+	cred := credentials{Token: resp.AuthedUser.AccessToken, RefreshToken: resp.AuthedUser.RefreshToken}
+	expiresIn := resp.AuthedUser.ExpiresIn
+	if cred.Token == "" {
+		cred.Token, cred.RefreshToken, expiresIn = resp.AccessToken, resp.RefreshToken, resp.ExpiresIn
+	}
+	if cred.Token == "" {
+		return credentials{}, errors.New("slack returned no user token, check oauth.userScopes")
+	}
+	if expiresIn > 0 {
+		cred.ExpiresAt = now.Add(time.Duration(expiresIn) * time.Second).Unix()
+	}
+	return cred, nil
+}
+
+// openBrowser opens u in the default browser; the URL is shown as well in
+// case this fails.
+func openBrowser(u string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u)
+	default:
+		cmd = exec.Command("xdg-open", u)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// loginCmd starts the login from the settings view. The callback goroutine
+// saves the token like the settings form does and reports to the TUI through
+// teaProgram.
+func loginCmd(path string, cfg config, confirmDelete bool) tea.Cmd {
+	return func() tea.Msg {
+		oc, err := oauthFor(cfg)
+		if err != nil {
+			return errMsg{err}
+		}
+		s, err := startLogin(oc)
+		if err != nil {
+			return errMsg{fmt.Errorf("login: %w", err)}
+		}
+		_ = openBrowser(s.URL)
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), loginTimeout)
+			defer cancel()
+			cred, team, err := s.wait(ctx)
+			var msg tea.Msg
+			if err != nil {
+				msg = loginFailedMsg{fmt.Errorf("login: %w", err)}
+			} else {
+				msg = saveConfigCmd(path, cfg, cred, confirmDelete)()
+				if upd, ok := msg.(configUpdatedMsg); ok {
+					upd.msg = "Logged in to " + missing(team, "Slack")
+					msg = upd
+				}
+			}
+			if teaProgram != nil {
+				teaProgram.Send(msg)
+			}
+		}()
+		return loginStartedMsg{URL: s.URL}
+	}
+}
+
+// ── Token rotation ───────────────────────────────────────────────────────────
+
+// refreshTokensCmd renews rotating (xoxe) tokens that expire soon and writes
+// them to config.json. Refresh tokens in revoked were rejected before and are
+// skipped until a login replaces them. It returns nil when nothing is due.
+func refreshTokensCmd(path string, cfg config, now time.Time, revoked map[string]string) tea.Cmd {
+	if cfg.OAuth == nil {
+		return nil
+	}
+	due := func(name, refresh string, expiresAt int64) bool {
+		return refresh != "" && expiresAt > 0 && now.Add(tokenRefreshMargin).Unix() >= expiresAt && revoked[name] != refresh
+	}
+	var slots []string // workspace names, "" = slackToken
+	if len(cfg.Workspaces) == 0 && due("", cfg.RefreshToken, cfg.TokenExpiresAt) {
+		slots = append(slots, "")
+	}
+	for _, wc := range cfg.Workspaces {
+		if due(wc.Name, wc.RefreshToken, wc.TokenExpiresAt) {
+			slots = append(slots, wc.Name)
+		}
+	}
+	if len(slots) == 0 {
+		return nil
+	}
+	oc := *cfg.OAuth
+	cfg.Workspaces = append([]workspaceConfig{}, cfg.Workspaces...)
+	return func() tea.Msg {
+		var errs []error
+		revoked := map[string]string{}
+		transient := false
+		for _, name := range slots {
+			refresh := cfg.RefreshToken
+			if name != "" {
+				refresh = cfg.Workspaces[workspaceIndex(cfg, name)].RefreshToken
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), slackCallTimeout)
+			resp, err := oauthAccess(ctx, oc, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refresh}})
+			cancel()
+			if err == nil {
+				var cred credentials
+				if cred, err = credentialsFrom(resp, time.Now()); err == nil {
+					cred.Workspace = name
+					err = cfg.setCredentials(cred)
+				}
+			}
+			switch {
+			case refreshRevoked(err):
+				revoked[name] = refresh
+				errs = append(errs, workspace{name: name}.wrap(fmt.Errorf("%w, log in again with ctrl+o", err)))
+			case err != nil:
+				transient = true
+				errs = append(errs, workspace{name: name}.wrap(err))
+			}
+		}
+		if len(errs) == len(slots) {
+			return tokensRefreshedMsg{err: fmt.Errorf("token refresh: %w", errors.Join(errs...)), revoked: revoked, transient: transient}
+		}
+		target := configPathForSave(path)
+		if err := writeConfig(target, cfg); err != nil {
+			return tokensRefreshedMsg{err: fmt.Errorf("token refresh: %w", err), revoked: revoked, transient: true}
+		}
+		msg := tokensRefreshedMsg{cfg: cfg, path: target, revoked: revoked, transient: transient}
+		if len(errs) > 0 {
+			msg.err = fmt.Errorf("token refresh: %w", errors.Join(errs...))
+		}
+		return msg
+	}
+}
+
+// refreshRevoked reports whether Slack rejected a refresh token for good; only
+// a new login helps then.
+func refreshRevoked(err error) bool {
+	var se slack.SlackErrorResponse
+	return errors.As(err, &se) && (se.Err == "invalid_grant" || se.Err == "invalid_refresh_token" || se.Err == "token_revoked")
+}
+
+// Transient refresh failures are retried after refreshBackoffMin, doubling up
+// to refreshBackoffMax.
+const (
+	refreshBackoffMin = time.Minute
+	refreshBackoffMax = 30 * time.Minute
+)
+
+// noteRefresh records the outcome of a token refresh.
+func (s refreshState) noteRefresh(msg tokensRefreshedMsg, now time.Time) refreshState {
+	if len(msg.revoked) > 0 {
+		revoked := make(map[string]string, len(s.revoked)+len(msg.revoked))
+		for k, v := range s.revoked {
+			revoked[k] = v
+		}
+		for k, v := range msg.revoked {
+			revoked[k] = v
+		}
+		s.revoked = revoked
+	}
+	if !msg.transient {
+		s.failures, s.retryAt = 0, time.Time{}
+		return s
+	}
+	wait := refreshBackoffMin << min(s.failures, 5)
+	if wait > refreshBackoffMax {
+		wait = refreshBackoffMax
+	}
+	s.failures++
+	s.retryAt = now.Add(wait)
+	return s
+}
+
+func workspaceIndex(cfg config, name string) int {
+	for i, wc := range cfg.Workspaces {
+		if strings.EqualFold(wc.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// primaryCredentials returns the token the settings form edits.
+func (cfg config) primaryCredentials() credentials {
+	if len(cfg.Workspaces) > 0 {
+		wc := cfg.Workspaces[0]
+		return credentials{Token: wc.Token, RefreshToken: wc.RefreshToken, ExpiresAt: wc.TokenExpiresAt}
+	}
+	return credentials{Token: cfg.SlackToken, RefreshToken: cfg.RefreshToken, ExpiresAt: cfg.TokenExpiresAt}
+}
+
+// setCredentials stores cred in its workspace, or in slackToken for a config
+// without workspaces. An empty workspace name means the primary one.
+func (cfg *config) setCredentials(cred credentials) error {
+	if len(cfg.Workspaces) == 0 {
+		if cred.Workspace != "" {
+			return fmt.Errorf("unknown workspace %q, config.json has no workspaces", cred.Workspace)
+		}
+		cfg.SlackToken, cfg.RefreshToken, cfg.TokenExpiresAt = cred.Token, cred.RefreshToken, cred.ExpiresAt
+		return nil
+	}
+	i := 0
+	if cred.Workspace != "" {
+		if i = workspaceIndex(*cfg, cred.Workspace); i < 0 {
+			return fmt.Errorf("unknown workspace %q", cred.Workspace)
+		}
+	}
+	cfg.Workspaces = append([]workspaceConfig{}, cfg.Workspaces...)
+	wc := &cfg.Workspaces[i]
+	wc.Token, wc.RefreshToken, wc.TokenExpiresAt = cred.Token, cred.RefreshToken, cred.ExpiresAt
+	return nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeOAuth stands in for Slack's oauth.v2.access. The first call can be
+// rate limited to check that the exchange goes through the retrying client.
+type fakeOAuth struct {
+	mu          sync.Mutex
+	requests    []url.Values
+	rateLimited bool
+}
+
+func (f *fakeOAuth) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/api/oauth.v2.access" {
+		http.NotFound(w, r)
+		return
+	}
+	f.mu.Lock()
+	if f.rateLimited {
+		f.rateLimited = false
+		f.mu.Unlock()
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	r.ParseForm()
+	f.requests = append(f.requests, r.PostForm)
+	f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.PostForm.Get("client_secret") != "secret":
+		fmt.Fprint(w, `{"ok":false,"error":"invalid_client"}`)
+	case r.PostForm.Get("code") == "good-code":
+		fmt.Fprint(w, `{"ok":true,"team":{"name":"Acme"},"authed_user":{"access_token":"xoxe.xoxp-1","refresh_token":"xoxe-1-r1","expires_in":43200}}`)
+	case r.PostForm.Get("grant_type") == "refresh_token" && r.PostForm.Get("refresh_token") == "xoxe-1-r1":
+		fmt.Fprint(w, `{"ok":true,"access_token":"xoxe.xoxp-2","refresh_token":"xoxe-1-r2","expires_in":43200}`)
+	default:
+		fmt.Fprint(w, `{"ok":false,"error":"invalid_grant"}`)
+	}
+}
+
+func (f *fakeOAuth) last() url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[len(f.requests)-1]
+}
+
+func (f *fakeOAuth) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.requests)
+}
+
+// newFakeLogin starts a login against the fake on a free local port.
+func newFakeLogin(t *testing.T) (*loginSession, *fakeOAuth) {
+	t.Helper()
+	fake := &fakeOAuth{}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	s, err := startLogin(oauthConfig{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  fmt.Sprintf("http://127.0.0.1:%d/callback", port),
+		AuthorizeURL: srv.URL + "/oauth/v2/authorize",
+		APIURL:       srv.URL + "/api",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.server.Close() })
+	return s, fake
+}
+
+// browserReturn plays the browser coming back from Slack.
+func browserReturn(t *testing.T, s *loginSession, state, code string) int {
+	t.Helper()
+	res, err := http.Get(s.redirect + "?" + url.Values{"state": {state}, "code": {code}}.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	return res.StatusCode
+}
+
+func TestLoginCodeExchange(t *testing.T) {
+	s, fake := newFakeLogin(t)
+	fake.rateLimited = true
+	authorize, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := authorize.Query(); q.Get("state") != s.state || q.Get("redirect_uri") != s.redirect || !strings.Contains(q.Get("user_scope"), "users.profile:write") {
+		t.Fatalf("authorize URL %s", s.URL)
+	}
+	if code := browserReturn(t, s, s.state, "good-code"); code != http.StatusOK {
+		t.Fatalf("callback answered %d", code)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cred, team, err := s.wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if team != "Acme" || cred.Token != "xoxe.xoxp-1" || cred.RefreshToken != "xoxe-1-r1" || cred.ExpiresAt == 0 {
+		t.Fatalf("got %+v from team %q", cred, team)
+	}
+	if got := fake.last(); got.Get("redirect_uri") != s.redirect || got.Get("client_id") != "client" {
+		t.Fatalf("exchange sent %v", got)
+	}
+}
+
+func TestLoginStateMismatch(t *testing.T) {
+	s, fake := newFakeLogin(t)
+	if code := browserReturn(t, s, "forged", "good-code"); code != http.StatusBadRequest {
+		t.Fatalf("forged state answered %d, want 400", code)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, _, err := s.wait(ctx); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("wait after forged callback: %v", err)
+	}
+	if fake.count() != 0 {
+		t.Fatal("code of a forged callback was exchanged")
+	}
+}
+
+func TestRefreshTokens(t *testing.T) {
+	fake := &fakeOAuth{rateLimited: true}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	now := time.Now()
+	cfg := config{
+		TokenStore:     tokenStorePlain,
+		SlackToken:     "xoxe.xoxp-1",
+		RefreshToken:   "xoxe-1-r1",
+		TokenExpiresAt: now.Add(5 * time.Minute).Unix(),
+		OAuth:          &oauthConfig{ClientID: "client", ClientSecret: "secret", APIURL: srv.URL + "/api"},
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	if cmd := refreshTokensCmd(path, config{OAuth: cfg.OAuth, SlackToken: "xoxp-static"}, now, nil); cmd != nil {
+		t.Fatal("refresh scheduled for a token that doesn't rotate")
+	}
+	cmd := refreshTokensCmd(path, cfg, now, nil)
+	if cmd == nil {
+		t.Fatal("no refresh for a token expiring in 5 minutes")
+	}
+	msg, ok := cmd().(tokensRefreshedMsg)
+	if !ok || msg.err != nil {
+		t.Fatalf("refresh: %#v", msg)
+	}
+	if msg.cfg.SlackToken != "xoxe.xoxp-2" || msg.cfg.RefreshToken != "xoxe-1-r2" || msg.cfg.TokenExpiresAt <= cfg.TokenExpiresAt {
+		t.Fatalf("refreshed config %+v", msg.cfg)
+	}
+	stored, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.SlackToken != "xoxe.xoxp-2" {
+		t.Fatalf("config.json holds %q", stored.SlackToken)
+	}
+}
+
+func TestRefreshTokensRevoked(t *testing.T) {
+	fake := &fakeOAuth{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	now := time.Now()
+	cfg := config{
+		TokenStore:     tokenStorePlain,
+		SlackToken:     "xoxe.xoxp-1",
+		RefreshToken:   "xoxe-1-revoked",
+		TokenExpiresAt: now.Add(5 * time.Minute).Unix(),
+		OAuth:          &oauthConfig{ClientID: "client", ClientSecret: "secret", APIURL: srv.URL + "/api"},
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	msg, ok := refreshTokensCmd(path, cfg, now, nil)().(tokensRefreshedMsg)
+	if !ok || msg.err == nil || !strings.Contains(msg.err.Error(), "ctrl+o") {
+		t.Fatalf("refresh with a revoked token: %#v", msg)
+	}
+	if msg.transient || msg.revoked[""] != "xoxe-1-revoked" {
+		t.Fatalf("revoked token not reported: %#v", msg)
+	}
+	var st refreshState
+	st = st.noteRefresh(msg, now)
+	if !st.retryAt.IsZero() {
+		t.Fatalf("a revoked token backs off until %s instead of waiting for a login", st.retryAt)
+	}
+	if refreshTokensCmd(path, cfg, now, st.revoked) != nil {
+		t.Fatal("revoked refresh token is tried again")
+	}
+	cfg.RefreshToken = "xoxe-1-r1"
+	if refreshTokensCmd(path, cfg, now, st.revoked) == nil {
+		t.Fatal("refresh token from a new login is not used")
+	}
+
+	// Transient failures back off, doubling up to the maximum.
+	for i, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute} {
+		st = st.noteRefresh(tokensRefreshedMsg{transient: true}, now)
+		if got := st.retryAt.Sub(now); got != want {
+			t.Fatalf("failure %d: retry after %s, want %s", i+1, got, want)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		st = st.noteRefresh(tokensRefreshedMsg{transient: true}, now)
+	}
+	if got := st.retryAt.Sub(now); got != refreshBackoffMax {
+		t.Fatalf("backoff grew to %s", got)
+	}
+	if st = st.noteRefresh(tokensRefreshedMsg{}, now); st.failures != 0 || !st.retryAt.IsZero() {
+		t.Fatalf("success kept the backoff: %+v", st)
+	}
+}
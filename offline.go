@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/slack-go/slack"
+)
+
+// ── Retries ──────────────────────────────────────────────────────────────────
+
+const (
+	// slackCallTimeout bounds one Slack action including its retries.
+	slackCallTimeout = 20 * time.Second
+	// attemptTimeout bounds a single request until Slack answers.
+	attemptTimeout = 5 * time.Second
+	maxAttempts    = 4
+	retryBackoff   = 500 * time.Millisecond
+)
+
+// slackHTTPClient is shared by all Slack clients: it retries rate-limited
+// and failed requests before slack-go sees them.
+var slackHTTPClient = &http.Client{Transport: newRetryTransport()}
+
+func newRetryTransport() retryTransport {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.ResponseHeaderTimeout = attemptTimeout
+	return retryTransport{base: base}
+}
+
+// retryTransport honours Retry-After on 429 and retries network errors and
+// 5xx answers with exponential backoff, as long as the request's context
+// leaves time for the wait. The last answer is passed on unchanged.
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+func (t retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		res, err := t.base.RoundTrip(req)
+		wait, retry := retryDelay(res, err, attempt)
+		if !retry || attempt == maxAttempts || req.Context().Err() != nil {
+			return res, err
+		}
+		if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < wait {
+			return res, err
+		}
+		if req.Body != nil {
+			if req.GetBody == nil {
+				return res, err
+			}
+			body, berr := req.GetBody()
+			if berr != nil {
+				return res, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		if res != nil {
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// retryDelay decides whether an answer is worth another attempt.
+func retryDelay(res *http.Response, err error, attempt int) (time.Duration, bool) {
+	backoff := retryBackoff << (attempt - 1)
+	switch {
+	case err != nil:
+		return backoff, !errors.Is(err, context.Canceled)
+	case res.StatusCode == http.StatusTooManyRequests:
+		if s, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && s >= 0 {
+			return time.Duration(s) * time.Second, true
+		}
+		return backoff, true
+	case res.StatusCode >= 500:
+		return backoff, true
+	}
+	return 0, false
+}
+
+// isTransient tells failures that may succeed later (offline, rate limited,
+// Slack down) from those that won't (bad token, invalid emoji).
+func isTransient(err error) bool {
+	var rl *slack.RateLimitedError
+	var sc slack.StatusCodeError
+	var ne net.Error
+	switch {
+	case errors.As(err, &rl):
+		return true
+	case errors.As(err, &sc):
+		return sc.Code >= 500
+	case errors.Is(err, context.DeadlineExceeded):
+		return true
+	case errors.As(err, &ne):
+		return true
+	}
+	return false
+}
+
+// ── Offline queue ────────────────────────────────────────────────────────────
+
+const offlineQueueName = "offline-queue.json"
+
+// errQueued marks a status write that was kept in the offline queue.
+var errQueued = errors.New("offline, queued for retry")
+
+// offlineMu serialises the read-modify-write of the queue file: status
+// writes of several workspaces fail in parallel.
+var offlineMu sync.Mutex
+
+// offlineQueuePathFor keeps the offline queue next to config.json.
+func offlineQueuePathFor(cfgPath string) string {
+	return filepath.Join(filepath.Dir(configPathForSave(cfgPath)), offlineQueueName)
+}
+
+func loadOfflineQueue(path string) ([]pendingWrite, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var items []pendingWrite
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("%s: %w", offlineQueueName, err)
+	}
+	return items, nil
+}
+
+// updateOfflineQueue applies fn to the stored queue and writes it back; an
+// empty queue removes the file.
+func updateOfflineQueue(path string, fn func([]pendingWrite) []pendingWrite) ([]pendingWrite, error) {
+	offlineMu.Lock()
+	defer offlineMu.Unlock()
+	items, err := loadOfflineQueue(path)
+	if err != nil {
+		return nil, err
+	}
+	items = fn(items)
+	if len(items) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		return nil, nil
+	}
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return items, os.WriteFile(path, data, 0o644)
+}
+
+// setStatus writes the status of ws. A write that fails because Slack can't
+// be reached is queued and replayed later; a successful one supersedes the
+// queued writes of the workspace.
+func (ws workspace) setStatus(ctx context.Context, text, emoji string, expiration int64) error {
+	err := ws.client.SetUserCustomStatusContext(ctx, text, emoji, expiration)
+	if ws.offlineQueue == "" {
+		return err
+	}
+	if err == nil {
+		if _, qerr := updateOfflineQueue(ws.offlineQueue, func(items []pendingWrite) []pendingWrite {
+			return withoutPending(items, func(p pendingWrite) bool { return p.Workspace == ws.name })
+		}); qerr != nil {
+			reportOfflineQueueError(ws, qerr)
+		}
+		return nil
+	}
+	if !isTransient(err) {
+		return err
+	}
+	w := pendingWrite{
+		ID:         newTemplateID(),
+		Workspace:  ws.name,
+		Text:       text,
+		Emoji:      emoji,
+		Expiration: expiration,
+		QueuedAt:   time.Now().Unix(),
+		LastError:  err.Error(),
+	}
+	if _, qerr := updateOfflineQueue(ws.offlineQueue, func(items []pendingWrite) []pendingWrite {
+		return append(items, w)
+	}); qerr != nil {
+		return errors.Join(err, qerr)
+	}
+	return fmt.Errorf("%w: %w", errQueued, err)
+}
+
+// reportOfflineQueueError surfaces a failed queue update after a successful
+// write: the status is set, only stale entries may be replayed later. Like
+// the login callback it reaches the TUI through teaProgram.
+func reportOfflineQueueError(ws workspace, err error) {
+	err = ws.wrap(fmt.Errorf("%s: %w", offlineQueueName, err))
+	if teaProgram != nil {
+		teaProgram.Send(errMsg{err})
+		return
+	}
+	fmt.Fprintln(os.Stderr, "warning:", err)
+}
+
+func withoutPending(items []pendingWrite, drop func(pendingWrite) bool) []pendingWrite {
+	out := items[:0]
+	for _, p := range items {
+		if !drop(p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func loadOfflineQueueCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		items, err := loadOfflineQueue(path)
+		return offlineQueueMsg{Items: items, Err: err}
+	}
+}
+
+// replayOfflineQueueCmd sends the queued writes in order. Per workspace it
+// stops at the first one Slack still can't take, so later writes never
+// overtake earlier ones; writes that expired meanwhile or that Slack rejects
+// are dropped.
+func replayOfflineQueueCmd(path string, workspaces []workspace) tea.Cmd {
+	return func() tea.Msg {
+		items, err := loadOfflineQueue(path)
+		if err != nil || len(items) == 0 {
+			return offlineQueueMsg{Items: items, Err: err}
+		}
+		done := map[string]bool{}
+		failed := map[string]pendingWrite{}
+		var errs []error
+		sent := 0
+		now := time.Now()
+		for _, p := range items {
+			if _, stop := failed[p.Workspace]; stop {
+				continue
+			}
+			ws, ok := findWorkspace(workspaces, p.Workspace)
+			if !ok || (p.Expiration > 0 && p.Expiration <= now.Unix()) {
+				done[p.ID] = true
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), slackCallTimeout)
+			err := ws.client.SetUserCustomStatusContext(ctx, p.Text, p.Emoji, p.Expiration)
+			cancel()
+			if err != nil && isTransient(err) {
+				p.Attempts++
+				p.LastError = err.Error()
+				failed[p.Workspace] = p
+				continue
+			}
+			done[p.ID] = true
+			if err != nil {
+				errs = append(errs, ws.wrap(fmt.Errorf("queued status %q: %w", p.Text, err)))
+				continue
+			}
+			sent++
+		}
+		items, err = updateOfflineQueue(path, func(items []pendingWrite) []pendingWrite {
+			items = withoutPending(items, func(p pendingWrite) bool { return done[p.ID] })
+			for i := range items {
+				if f, ok := failed[items[i].Workspace]; ok && items[i].ID == f.ID {
+					items[i] = f
+				}
+			}
+			return items
+		})
+		return offlineQueueMsg{Items: items, Sent: sent, Err: errors.Join(append(errs, err)...)}
+	}
+}
+
+func findWorkspace(workspaces []workspace, name string) (workspace, bool) {
+	for _, ws := range workspaces {
+		if ws.name == name {
+			return ws, true
+		}
+	}
+	return workspace{}, false
+}
+
+// handleOfflineQueue shows the queue and reports replayed writes.
+func (m model) handleOfflineQueue(msg offlineQueueMsg) (model, tea.Cmd) {
+	m.offlineQueue = msg.Items
+	m.replayingOffline = false
+	if msg.Err != nil {
+		m.err = msg.Err
+	}
+	if msg.Sent == 0 {
+		return m, nil
+	}
+	m.message = fmt.Sprintf("Sent %d queued status update(s)", msg.Sent)
+	m.markOwnWrite(workspaceNames(m.workspaces)...)
+	return m, m.fetchStatusesCmd()
+}
+
+// offlineQueueLine summarises the queue for the status card.
+func offlineQueueLine(items []pendingWrite) string {
+	if len(items) == 0 {
+		return ""
+	}
+	last := items[len(items)-1]
+	line := fmt.Sprintf("Offline: %d status update(s) queued since %s", len(items), time.Unix(items[0].QueuedAt, 0).Local().Format("15:04"))
+	if last.LastError != "" {
+		line += " (" + last.LastError + ")"
+	}
+	return line
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/slack-go/slack"
+)
+
+// Presence values accepted by users.setPresence.
+const (
+	presenceAuto = "auto"
+	presenceAway = "away"
+)
+
+// parsePresence reads the presence field of the forms; "" leaves it alone.
+func parsePresence(v string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "":
+		return "", nil
+	case presenceAuto, "aktiv", "active":
+		return presenceAuto, nil
+	case presenceAway, "abwesend":
+		return presenceAway, nil
+	}
+	return "", fmt.Errorf("presence: expected %s or %s, got %q", presenceAuto, presenceAway, v)
+}
+
+// users.getPresence needs the user id; auth.test is asked once per client.
+var (
+	selfUserIDsMu sync.Mutex
+	selfUserIDs   = map[*slack.Client]string{}
+)
+
+func selfUserID(ctx context.Context, client *slack.Client) (string, error) {
+	selfUserIDsMu.Lock()
+	id, ok := selfUserIDs[client]
+	selfUserIDsMu.Unlock()
+	if ok {
+		return id, nil
+	}
+	auth, err := client.AuthTestContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	selfUserIDsMu.Lock()
+	selfUserIDs[client] = auth.UserID
+	selfUserIDsMu.Unlock()
+	return auth.UserID, nil
+}
+
+// currentPresence returns the presence Slack shows ("away" also when idle)
+// and whether the user set themselves away.
+func currentPresence(ctx context.Context, client *slack.Client) (string, bool, error) {
+	id, err := selfUserID(ctx, client)
+	if err != nil {
+		return "", false, err
+	}
+	p, err := client.GetUserPresenceContext(ctx, id)
+	if err != nil {
+		return "", false, err
+	}
+	return p.Presence, p.ManualAway, nil
+}
+
+// restorablePresence is the value to set again later: "away" only if the user
+// set it; idle auto-away counts as "auto", restoring it must not pin the user
+// away.
+func restorablePresence(presence string, manualAway bool) string {
+	switch {
+	case presence == "":
+		return ""
+	case manualAway:
+		return presenceAway
+	}
+	return presenceAuto
+}
+
+func fetchPresenceCmd(client *slack.Client) tea.Cmd {
+	return func() tea.Msg {
+		if client == nil {
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		p, manual, err := currentPresence(ctx, client)
+		if err != nil {
+			return errMsg{fmt.Errorf("presence: %w", err)}
+		}
+		return presenceMsg{Presence: p, ManualAway: manual}
+	}
+}
+
+// setPresenceCmd sets presence in all targets; an empty value is a no-op.
+func setPresenceCmd(targets []workspace, presence string) tea.Cmd {
+	if presence == "" {
+		return nil
+	}
+	return broadcastCmd(targets, actionPresence, func(ctx context.Context, ws workspace) error {
+		return ws.client.SetUserPresenceContext(ctx, presence)
+	})
+}
+
+// presenceLabel renders presence for the status card.
+func presenceLabel(p string, manualAway bool) string {
+	switch {
+	case p == presenceAway && manualAway:
+		return "away"
+	case p == presenceAway:
+		return "away (idle)"
+	case p == "active" || p == presenceAuto:
+		return "active (auto)"
+	}
+	return "unknown"
+}
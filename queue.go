@@ -0,0 +1,419 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	queueName = "status-queue.json"
+
+	lateApply = "apply"
+	lateSkip  = "skip"
+)
+
+// The queue file is the source of truth: the CLI may add or cancel items
+// while the TUI or the daemon runs, so every change is a read-modify-write
+// under a lock file, and due items are claimed (removed) before they are
+// applied so that only one running instance applies each.
+
+// queueLockStale is when a lock file is considered left over from a crash.
+const queueLockStale = 30 * time.Second
+
+// queuePathFor keeps the queue next to config.json.
+func queuePathFor(cfgPath string) string {
+	return filepath.Join(filepath.Dir(configPathForSave(cfgPath)), queueName)
+}
+
+func loadQueue(path string) ([]queuedStatus, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var items []queuedStatus
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("%s: %w", queueName, err)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].At < items[j].At })
+	return items, nil
+}
+
+func saveQueue(path string, items []queuedStatus) error {
+	if len(items) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Readers don't take the lock; replace the file in one step.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// lockQueue takes the lock file next to the queue, waiting a few seconds for
+// another process to release it.
+func lockQueue(path string) (unlock func(), err error) {
+	lock := path + ".lock"
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if fi, err := os.Stat(lock); err == nil && time.Since(fi.ModTime()) > queueLockStale {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is locked by another process", queueName)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// updateQueue applies fn to the stored queue and writes the result back.
+func updateQueue(path string, fn func([]queuedStatus) []queuedStatus) ([]queuedStatus, error) {
+	unlock, err := lockQueue(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	items, err := loadQueue(path)
+	if err != nil {
+		return nil, err
+	}
+	items = fn(items)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].At < items[j].At })
+	return items, saveQueue(path, items)
+}
+
+func loadQueueCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		items, err := loadQueue(path)
+		return queueLoadedMsg{Items: items, Err: err}
+	}
+}
+
+// claimDueQueuedCmd reloads the queue and takes the items due at now out of
+// the file; the model applies them from the message.
+func claimDueQueuedCmd(path string, now time.Time) tea.Cmd {
+	return func() tea.Msg {
+		items, err := loadQueue(path)
+		if err != nil || len(items) == 0 || items[0].At > now.Unix() {
+			return queueLoadedMsg{Items: items, Err: err}
+		}
+		var due []queuedStatus
+		items, err = updateQueue(path, func(items []queuedStatus) []queuedStatus {
+			var rest []queuedStatus
+			for _, q := range items {
+				if q.At <= now.Unix() {
+					due = append(due, q)
+				} else {
+					rest = append(rest, q)
+				}
+			}
+			return rest
+		})
+		if err != nil {
+			return queueLoadedMsg{Err: err}
+		}
+		return queueLoadedMsg{Items: items, Due: due}
+	}
+}
+
+func updateQueueCmd(path string, fn func([]queuedStatus) []queuedStatus) tea.Cmd {
+	return func() tea.Msg {
+		items, err := updateQueue(path, fn)
+		return queueLoadedMsg{Items: items, Err: err}
+	}
+}
+
+// putQueued adds q or replaces the item with the same id.
+func putQueued(q queuedStatus) func([]queuedStatus) []queuedStatus {
+	return func(items []queuedStatus) []queuedStatus {
+		for i := range items {
+			if items[i].ID == q.ID {
+				items[i] = q
+				return items
+			}
+		}
+		return append(items, q)
+	}
+}
+
+func removeQueued(ids ...string) func([]queuedStatus) []queuedStatus {
+	return func(items []queuedStatus) []queuedStatus {
+		out := items[:0]
+		for _, q := range items {
+			drop := false
+			for _, id := range ids {
+				drop = drop || q.ID == id
+			}
+			if !drop {
+				out = append(out, q)
+			}
+		}
+		return out
+	}
+}
+
+// newQueuedStatus validates a set-later request. when is an expiry expression
+// relative to now; until is resolved relative to the start, so "11:30" or
+// "90m" mean the same day or 90 minutes after it.
+func newQueuedStatus(when, ref, text, emoji, until, late string, now time.Time, rollover bool, templates []template) (queuedStatus, error) {
+	at, err := parseExpiryExpr(when, now, rollover)
+	if err != nil {
+		return queuedStatus{}, fmt.Errorf("when: %w", err)
+	}
+	if !at.After(now) {
+		return queuedStatus{}, errors.New("when must be in the future")
+	}
+	q := queuedStatus{ID: newTemplateID(), At: at.Unix(), Text: strings.TrimSpace(text), Emoji: strings.TrimSpace(emoji)}
+	if strings.TrimSpace(until) != "" {
+		exp, err := parseExpiryExpr(until, at, rollover)
+		if err != nil {
+			return queuedStatus{}, fmt.Errorf("until: %w", err)
+		}
+		if !exp.After(at) {
+			return queuedStatus{}, errors.New("until must be after the start")
+		}
+		q.Until = exp.Unix()
+	}
+	switch strings.ToLower(strings.TrimSpace(late)) {
+	case "", lateApply:
+		q.Late = lateApply
+	case lateSkip:
+		q.Late = lateSkip
+	default:
+		return queuedStatus{}, fmt.Errorf("late: expected %s or %s", lateApply, lateSkip)
+	}
+	if ref = strings.TrimSpace(ref); ref != "" {
+		t, ok := findTemplate(templates, ref)
+		if !ok {
+			return queuedStatus{}, fmt.Errorf("template %q not found", ref)
+		}
+		if t.UseDurationSelector && q.Until == 0 {
+			return queuedStatus{}, fmt.Errorf("template %q asks for a duration; set until", t.Label)
+		}
+		q.Template, q.Label = t.ID, t.Label
+		return q, nil
+	}
+	if q.Text == "" || q.Emoji == "" {
+		return queuedStatus{}, errors.New("template or text and emoji are required")
+	}
+	if err := validateStatusText(q.Text); err != nil {
+		return queuedStatus{}, err
+	}
+	return q, nil
+}
+
+// describe is the one-line summary used by the queue view and the CLI.
+func (q queuedStatus) describe(now time.Time) string {
+	what := missing(q.Label, q.Template)
+	if what == "" {
+		what = renderEmoji(strings.TrimSpace(q.Emoji + " " + q.Text))
+	}
+	s := formatExpiry(time.Unix(q.At, 0), now) + "  " + what
+	if q.Until > 0 {
+		s += " (bis " + formatExpiry(time.Unix(q.Until, 0), now) + ")"
+	}
+	if q.Late == lateSkip {
+		s += " [skip if late]"
+	}
+	return s
+}
+
+// handleQueueLoaded stores the queue and applies the items claimed on a
+// clock tick. Like follow-ups, items wait while cal-sync shows a meeting, so
+// no items are claimed then.
+func (m model) handleQueueLoaded(msg queueLoadedMsg) (model, tea.Cmd) {
+	if msg.Err != nil {
+		m.err = fmt.Errorf("queue: %w", msg.Err)
+		return m, nil
+	}
+	m.queue = msg.Items
+	if m.queueCursor >= len(m.queue) {
+		m.queueCursor = max(len(m.queue)-1, 0)
+	}
+	now := time.Now()
+	var cmds []tea.Cmd
+	for _, q := range msg.Due {
+		late := now.Sub(time.Unix(q.At, 0)) > scheduleOnTime
+		if late && q.Late == lateSkip {
+			m.message = "Skipped late queued status: " + q.describe(now)
+			continue
+		}
+		if q.Until > 0 && q.Until <= now.Unix() {
+			m.message = "Skipped queued status, already over: " + q.describe(now)
+			continue
+		}
+		var cmd tea.Cmd
+		m, cmd = m.applyQueued(q, now)
+		cmds = append(cmds, cmd)
+	}
+	// Due items go out one after the other, so the latest one ends up as the
+	// status after a catch-up.
+	return m, tea.Sequence(cmds...)
+}
+
+func (m model) applyQueued(q queuedStatus, now time.Time) (model, tea.Cmd) {
+	var exp time.Time
+	if q.Until > 0 {
+		exp = time.Unix(q.Until, 0)
+	}
+	if q.Template != "" {
+		t, ok := findTemplate(m.templates, q.Template)
+		if !ok {
+			return m.withError(fmt.Errorf("queued template %q no longer exists", missing(q.Label, q.Template))), nil
+		}
+		if exp.IsZero() {
+			var err error
+			if exp, err = m.templateExpiry(t, now); err != nil {
+				return m.withError(fmt.Errorf("%s: %w", t.Label, err)), nil
+			}
+		}
+		m.message = "Queued status: " + t.Label
+		return m.setStatusWithFollowUp(t, exp, nil)
+	}
+	m.message = "Queued status: " + q.Text
+	m.followUps = nil
+	return m, tea.Batch(
+		setStatusCmd(m.workspaces, q.Text, q.Emoji, exp, m.textVars()),
+		saveFollowUpsCmd(m.followUpsPath, m.followUps),
+	)
+}
+
+// ── Queue view and set-later form ────────────────────────────────────────────
+
+func (m model) enterQueueView() (model, tea.Cmd) {
+	m.state = viewQueue
+	m.message = "n new \a e edit \a x cancel \a Esc back"
+	return m, loadQueueCmd(m.queuePath)
+}
+
+func (m model) enterSetLaterForm(q *queuedStatus) model {
+	m.state = viewSetLater
+	m.editingQueueID = ""
+	m.message = "Set a status for later"
+	if q != nil {
+		m.editingQueueID = q.ID
+		m.message = "Edit queued status"
+	}
+	m.inputs = buildSetLaterInputs(q)
+	m.focusIndex = 0
+	return m
+}
+
+func (m model) handleQueueKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		return m.backToDashboard(), nil
+	case "up", "k":
+		if m.queueCursor > 0 {
+			m.queueCursor--
+		}
+	case "down", "j":
+		if m.queueCursor < len(m.queue)-1 {
+			m.queueCursor++
+		}
+	case "n", "a":
+		return m.enterSetLaterForm(nil), nil
+	case "e", "enter":
+		if m.queueCursor < len(m.queue) {
+			q := m.queue[m.queueCursor]
+			return m.enterSetLaterForm(&q), nil
+		}
+	case "x", "d", "delete", "backspace":
+		if m.queueCursor < len(m.queue) {
+			q := m.queue[m.queueCursor]
+			m.message = "Cancelled: " + q.describe(time.Now())
+			return m, updateQueueCmd(m.queuePath, removeQueued(q.ID))
+		}
+	}
+	return m, nil
+}
+
+func (m model) submitSetLaterForm() (tea.Model, tea.Cmd) {
+	v := func(i int) string { return m.inputs[i].Value() }
+	q, err := newQueuedStatus(v(0), v(1), v(2), v(3), v(4), v(5), time.Now(), effectiveRollOver(m.cfg), m.templates)
+	if err != nil {
+		return m.withError(err), nil
+	}
+	if m.editingQueueID != "" {
+		q.ID = m.editingQueueID
+	}
+	m.editingQueueID = ""
+	m.inputs = nil
+	m.state = viewQueue
+	m.message = "Queued: " + q.describe(time.Now())
+	m.err = nil
+	return m, updateQueueCmd(m.queuePath, putQueued(q))
+}
+
+// setLaterPreview resolves the when/until fields while typing.
+func (m model) setLaterPreview() string {
+	if len(m.inputs) < 5 {
+		return ""
+	}
+	now := time.Now()
+	at, err := parseExpiryExpr(m.inputs[0].Value(), now, effectiveRollOver(m.cfg))
+	if err != nil {
+		return "Start: " + err.Error()
+	}
+	s := "Start: " + formatExpiry(at, now)
+	if strings.TrimSpace(m.inputs[4].Value()) != "" {
+		exp, err := parseExpiryExpr(m.inputs[4].Value(), at, effectiveRollOver(m.cfg))
+		if err != nil {
+			return s + " \a Bis: " + err.Error()
+		}
+		s += " \a Bis: " + formatExpiry(exp, now)
+	}
+	return s
+}
+
+func renderQueueView(items []queuedStatus, cursor int, message string) string {
+	var b strings.Builder
+	b.WriteString(renderPanelTitle("Geplante Status"))
+	b.WriteString("\n\n")
+	now := time.Now()
+	if len(items) == 0 {
+		b.WriteString("Nichts geplant. n für neuen Eintrag.\n")
+	}
+	for i, q := range items {
+		line := "  " + q.describe(now)
+		if i == cursor {
+			line = lipgloss.NewStyle().Foreground(lipgloss.Color("#a6da95")).Bold(true).Render("> " + q.describe(now))
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#8aadf4")).Render(message))
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#7dc4e4")).
+		Padding(1, 2).
+		Width(80).
+		Render(b.String())
+}
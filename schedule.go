@@ -0,0 +1,392 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	scheduleName      = "schedule.json"
+	scheduleStateName = "schedule-state.json"
+
+	catchUpLatest = "latest"
+	catchUpAll    = "all"
+	catchUpSkip   = "skip"
+
+	// scheduleLookahead bounds the upcoming list and scheduleLookback the
+	// catch-up scan after sleep or downtime.
+	scheduleLookahead = 7 * 24 * time.Hour
+	scheduleLookback  = 7 * 24 * time.Hour
+	// scheduleOnTime is how late a run may be and still count as on time.
+	scheduleOnTime = 2 * clockTickInterval
+)
+
+func loadScheduleConfig(path string) (scheduleConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return scheduleConfig{}, err
+	}
+	var cfg scheduleConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return scheduleConfig{}, fmt.Errorf("%s: %w", scheduleName, err)
+	}
+	if cfg.CatchUp == "" {
+		cfg.CatchUp = catchUpLatest
+	}
+	if cfg.MaxLateMinutes <= 0 {
+		cfg.MaxLateMinutes = 240
+	}
+	var problems []string
+	for i := range cfg.Rules {
+		if err := cfg.Rules[i].compile(); err != nil {
+			problems = append(problems, fmt.Sprintf("rule %d (%s): %v", i+1, cfg.Rules[i].Template, err))
+		}
+	}
+	if !validCatchUp(cfg.CatchUp) {
+		problems = append(problems, fmt.Sprintf("unknown catchUp %q", cfg.CatchUp))
+	}
+	if len(problems) > 0 {
+		return cfg, errors.New(scheduleName + ": " + strings.Join(problems, "; "))
+	}
+	return cfg, nil
+}
+
+// loadSchedule reads the optional schedule.json and its state file, which sits
+// next to it and remembers the last check for catch-up after downtime.
+func loadSchedule(now time.Time) (scheduleState, error) {
+	path, err := resolvePath(scheduleName)
+	if err != nil {
+		return scheduleState{}, nil
+	}
+	cfg, err := loadScheduleConfig(path)
+	st := scheduleState{
+		cfg:       cfg,
+		enabled:   cfg.Enabled == nil || *cfg.Enabled,
+		statePath: filepath.Join(filepath.Dir(path), scheduleStateName),
+	}
+	st.lastCheck = loadScheduleState(st.statePath)
+	if st.enabled {
+		st = st.refreshUpcoming(now)
+	}
+	return st, err
+}
+
+// refreshUpcoming drops the runs that have passed. The week is only scanned
+// again on a new day: the rules don't change while running.
+func (s scheduleState) refreshUpcoming(now time.Time) scheduleState {
+	if day := now.Format("2006-01-02"); day != s.upcomingDay {
+		s.upcoming = scheduleRuns(s.cfg.Rules, now, now.Add(scheduleLookahead))
+		s.upcomingDay = day
+		return s
+	}
+	for len(s.upcoming) > 0 && !s.upcoming[0].At.After(now) {
+		s.upcoming = s.upcoming[1:]
+	}
+	return s
+}
+
+func validCatchUp(p string) bool {
+	return p == catchUpLatest || p == catchUpAll || p == catchUpSkip
+}
+
+// compile parses the textual fields of a rule once.
+func (r *scheduleRule) compile() error {
+	if r.Template == "" {
+		return errors.New("template missing")
+	}
+	if r.CatchUp != "" && !validCatchUp(r.CatchUp) {
+		return fmt.Errorf("unknown catchUp %q", r.CatchUp)
+	}
+	if (r.Cron == "") == (r.At == "") {
+		return errors.New("set either at or cron")
+	}
+	if r.Cron != "" {
+		c, err := parseCron(r.Cron)
+		if err != nil {
+			return err
+		}
+		r.cron = &c
+	} else {
+		t, err := time.Parse("15:04", r.At)
+		if err != nil {
+			return fmt.Errorf("at %q: expected HH:MM", r.At)
+		}
+		r.atMinute = t.Hour()*60 + t.Minute()
+		r.days = 0
+		for _, d := range r.Days {
+			switch strings.ToLower(d) {
+			case "weekdays", "werktags":
+				r.days |= 1<<time.Monday | 1<<time.Tuesday | 1<<time.Wednesday | 1<<time.Thursday | 1<<time.Friday
+			case "workdays", "arbeitstage":
+				r.days |= 1<<time.Monday | 1<<time.Tuesday | 1<<time.Wednesday | 1<<time.Thursday | 1<<time.Friday
+				r.SkipHolidays = true
+			case "weekend", "wochenende":
+				r.days |= 1<<time.Saturday | 1<<time.Sunday
+			default:
+				wd, ok := weekdayKeys[strings.ToLower(d)]
+				if !ok {
+					return fmt.Errorf("unknown day %q", d)
+				}
+				r.days |= 1 << wd
+			}
+		}
+		if r.days == 0 {
+			r.days = 0x7f
+		}
+	}
+	for _, d := range append([]string{r.From, r.To}, r.Except...) {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return fmt.Errorf("date %q: expected YYYY-MM-DD", d)
+		}
+	}
+	r.valid = true
+	return nil
+}
+
+// matches reports whether the rule fires at the minute t.
+func (r scheduleRule) matches(t time.Time) bool {
+	day := t.Format("2006-01-02")
+	if (r.From != "" && day < r.From) || (r.To != "" && day > r.To) {
+		return false
+	}
+	for _, ex := range r.Except {
+		if ex == day {
+			return false
+		}
+	}
+	var ok bool
+	if r.cron != nil {
+		ok = r.cron.matches(t)
+	} else {
+		ok = t.Hour()*60+t.Minute() == r.atMinute && r.days&(1<<t.Weekday()) != 0
+	}
+	return ok && !(r.SkipHolidays && isHoliday(t))
+}
+
+// scheduleRuns lists the runs of all rules in (from, to], ordered by time.
+// The scan walks real minutes, so wall-clock times skipped by the switch to
+// summer time don't run; the hour repeated in autumn runs only once.
+func scheduleRuns(rules []scheduleRule, from, to time.Time) []scheduledRun {
+	var runs []scheduledRun
+	start := from.Truncate(time.Minute).Add(time.Minute)
+	var lastWall int64
+	for t := start; !t.After(to); t = t.Add(time.Minute) {
+		_, offset := t.Zone()
+		wall := t.Unix() + int64(offset)
+		if wall <= lastWall {
+			continue
+		}
+		lastWall = wall
+		for i, r := range rules {
+			if r.valid && r.matches(t) {
+				runs = append(runs, scheduledRun{Rule: i, At: t, Template: r.Template})
+			}
+		}
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].At.Before(runs[j].At) })
+	return runs
+}
+
+// selectRuns applies the catch-up policy: on-time runs always execute, late
+// ones according to the rule's (or the global) policy and maxLateMinutes.
+func selectRuns(cfg scheduleConfig, runs []scheduledRun, now time.Time) []scheduledRun {
+	maxLate := time.Duration(cfg.MaxLateMinutes) * time.Minute
+	var out []scheduledRun
+	var latest *scheduledRun
+	for i, run := range runs {
+		late := now.Sub(run.At)
+		if late <= scheduleOnTime {
+			out = append(out, run)
+			continue
+		}
+		policy := cfg.CatchUp
+		if p := cfg.Rules[run.Rule].CatchUp; p != "" {
+			policy = p
+		}
+		if late > maxLate || policy == catchUpSkip {
+			continue
+		}
+		if policy == catchUpAll {
+			out = append(out, run)
+			continue
+		}
+		latest = &runs[i]
+	}
+	if latest != nil {
+		out = append([]scheduledRun{*latest}, out...)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out
+}
+
+func loadScheduleState(path string) time.Time {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}
+	}
+	var st struct {
+		LastCheck int64 `json:"lastCheck"`
+	}
+	if json.Unmarshal(data, &st) != nil || st.LastCheck == 0 {
+		return time.Time{}
+	}
+	return time.Unix(st.LastCheck, 0)
+}
+
+func saveScheduleStateCmd(path string, lastCheck time.Time) tea.Cmd {
+	return func() tea.Msg {
+		data, _ := json.Marshal(struct {
+			LastCheck int64 `json:"lastCheck"`
+		}{lastCheck.Unix()})
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return errMsg{fmt.Errorf("schedule state: %w", err)}
+		}
+		return nil
+	}
+}
+
+// handleScheduleTick runs due scheduled templates. Like follow-ups it waits
+// while cal-sync shows a meeting; the runs are then late and handled by the
+// catch-up policy.
+func (m model) handleScheduleTick(now time.Time) (model, tea.Cmd) {
+	if !m.schedule.enabled {
+		return m, nil
+	}
+	m.schedule = m.schedule.refreshUpcoming(now)
+	if m.calSync.ActiveEventID != "" {
+		return m, nil
+	}
+	// A running vacation wins: its runs are dropped, not caught up later.
+	if m.vacation != nil && m.vacation.active(now) {
+		m.schedule.lastCheck = now
+		return m, saveScheduleStateCmd(m.schedule.statePath, now)
+	}
+	from := m.schedule.lastCheck
+	if from.IsZero() || now.Sub(from) > scheduleLookback {
+		from = now.Add(-scheduleLookback)
+	}
+	runs := selectRuns(m.schedule.cfg, scheduleRuns(m.schedule.cfg.Rules, from, now), now)
+	m.schedule.lastCheck = now
+	// The runs go out one after the other, so the latest one ends up as the
+	// status and its follow-up is the one kept.
+	var applies []tea.Cmd
+	for _, run := range runs {
+		t, ok := findTemplate(m.templates, run.Template)
+		if !ok {
+			m.err = fmt.Errorf("schedule: template %q not found", run.Template)
+			continue
+		}
+		if t.UseDurationSelector {
+			m.err = fmt.Errorf("schedule: template %q asks for a duration and can't run unattended", t.Label)
+			continue
+		}
+		var cmd tea.Cmd
+		m, cmd = m.applyTemplate(t)
+		m.message = fmt.Sprintf("Scheduled: %s (%s)", t.Label, run.At.Format("15:04"))
+		applies = append(applies, cmd)
+	}
+	return m, tea.Batch(saveScheduleStateCmd(m.schedule.statePath, now), tea.Sequence(applies...))
+}
+
+// ── Cron expressions ─────────────────────────────────────────────────────────
+
+// cronSpec is a parsed five-field cron expression (minute hour day-of-month
+// month day-of-week) as bit sets.
+type cronSpec struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+func parseCron(expr string) (cronSpec, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return cronSpec{}, fmt.Errorf("cron %q: expected 5 fields", expr)
+	}
+	var c cronSpec
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return cronSpec{}, fmt.Errorf("cron minute: %w", err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return cronSpec{}, fmt.Errorf("cron hour: %w", err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return cronSpec{}, fmt.Errorf("cron day of month: %w", err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return cronSpec{}, fmt.Errorf("cron month: %w", err)
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return cronSpec{}, fmt.Errorf("cron day of week: %w", err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1 // 7 = Sunday
+	}
+	c.domStar = fields[2] == "*"
+	c.dowStar = fields[4] == "*"
+	return c, nil
+}
+
+// parseCronField parses "*", "5", "1-5", "*/15", "1-10/2" and comma lists.
+func parseCronField(f string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(f, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			s, err := strconv.Atoi(stepStr)
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("bad step %q", part)
+			}
+			step = s
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(a); err != nil {
+				return 0, fmt.Errorf("bad value %q", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(b); err != nil {
+					return 0, fmt.Errorf("bad value %q", part)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (c cronSpec) matches(t time.Time) bool {
+	if c.minute&(1<<t.Minute()) == 0 || c.hour&(1<<t.Hour()) == 0 || c.month&(1<<int(t.Month())) == 0 {
+		return false
+	}
+	domOK := c.dom&(1<<t.Day()) != 0
+	dowOK := c.dow&(1<<int(t.Weekday())) != 0
+	// Classic cron: if both day fields are restricted, either may match.
+	if !c.domStar && !c.dowStar {
+		return domOK || dowOK
+	}
+	return domOK && dowOK
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	loc := mustLoadLocation(t, "Europe/Berlin")
+	at := func(month time.Month, day, h, min int) time.Time {
+		return time.Date(2026, month, day, h, min, 0, 0, loc)
+	}
+	tests := []struct {
+		expr string
+		at   time.Time
+		want bool
+	}{
+		{"*/15 9-17 * * 1-5", at(3, 25, 9, 45), true},
+		{"*/15 9-17 * * 1-5", at(3, 25, 9, 50), false},
+		{"*/15 9-17 * * 1-5", at(3, 25, 18, 0), false},
+		{"*/15 9-17 * * 1-5", at(3, 28, 10, 0), false},
+		{"0 8,12 * * *", at(3, 28, 12, 0), true},
+		{"5/20 * * * *", at(3, 28, 12, 45), true},
+		{"5/20 * * * *", at(3, 28, 12, 0), false},
+		// Day of month and weekday both restricted: either one matches.
+		{"0 8 1 * 1", at(3, 2, 8, 0), true},
+		{"0 8 1 * 1", at(3, 1, 8, 0), true},
+		{"0 8 1 * 1", at(3, 3, 8, 0), false},
+		{"30 7 * * 7", at(3, 29, 7, 30), true},
+		{"0 0 24 12 *", at(12, 24, 0, 0), true},
+		{"0 0 24 12 *", at(11, 24, 0, 0), false},
+	}
+	for _, tt := range tests {
+		c, err := parseCron(tt.expr)
+		if err != nil {
+			t.Errorf("parseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := c.matches(tt.at); got != tt.want {
+			t.Errorf("%q matches %s = %v, want %v", tt.expr, tt.at.Format("Mon 02.01. 15:04"), got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "* * * 13 *"} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q) accepted an invalid expression", expr)
+		}
+	}
+}
+
+func TestSelectRuns(t *testing.T) {
+	now := time.Date(2026, 3, 25, 12, 0, 0, 0, time.UTC)
+	at := func(h, min int) time.Time { return time.Date(2026, 3, 25, h, min, 0, 0, time.UTC) }
+	// Rule 0 follows the global policy, rule 1 catches up all, rule 2 skips.
+	rules := []scheduleRule{{}, {CatchUp: catchUpAll}, {CatchUp: catchUpSkip}}
+	runs := []scheduledRun{
+		{Rule: 0, At: at(7, 0)},
+		{Rule: 0, At: at(9, 0)},
+		{Rule: 1, At: at(9, 30)},
+		{Rule: 0, At: at(10, 0)},
+		{Rule: 1, At: at(10, 30)},
+		{Rule: 2, At: at(11, 0)},
+		{Rule: 2, At: at(11, 59)},
+	}
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{catchUpLatest, "1@09:30 0@10:00 1@10:30 2@11:59"},
+		{catchUpAll, "0@09:00 1@09:30 0@10:00 1@10:30 2@11:59"},
+		{catchUpSkip, "1@09:30 1@10:30 2@11:59"},
+	}
+	for _, tt := range tests {
+		cfg := scheduleConfig{CatchUp: tt.policy, MaxLateMinutes: 240, Rules: rules}
+		var got []string
+		for _, r := range selectRuns(cfg, runs, now) {
+			got = append(got, fmt.Sprintf("%d@%s", r.Rule, r.At.Format("15:04")))
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("catchUp %s: got %v, want %s", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleRunsDST(t *testing.T) {
+	loc := mustLoadLocation(t, "Europe/Berlin")
+	rules := []scheduleRule{
+		{Template: "night", At: "02:30"},
+		{Template: "hourly", Cron: "0 * * * *"},
+		{Template: "morning", At: "09:00", Days: []string{"weekdays", "weekend"}},
+	}
+	for i := range rules {
+		if err := rules[i].compile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	count := func(runs []scheduledRun, template string) (n int, times []string) {
+		for _, r := range runs {
+			if r.Template == template {
+				n++
+				times = append(times, r.At.UTC().Format("02.01. 15:04Z"))
+			}
+		}
+		return n, times
+	}
+	tests := []struct {
+		name          string
+		from, to      time.Time
+		night, hourly int
+		morningUTC    string
+	}{
+		// 29 March: 02:00 jumps to 03:00, so 02:30 and the 02:00 run don't exist.
+		{"spring", time.Date(2026, 3, 28, 23, 0, 0, 0, loc), time.Date(2026, 3, 29, 12, 0, 0, 0, loc), 0, 12, "29.03. 07:00Z"},
+		// 25 October: 03:00 goes back to 02:00; the repeated hour runs once.
+		{"autumn", time.Date(2026, 10, 24, 23, 0, 0, 0, loc), time.Date(2026, 10, 25, 12, 0, 0, 0, loc), 1, 13, "25.10. 08:00Z"},
+	}
+	for _, tt := range tests {
+		runs := scheduleRuns(rules, tt.from, tt.to)
+		if n, times := count(runs, "night"); n != tt.night {
+			t.Errorf("%s: 02:30 ran %d times %v, want %d", tt.name, n, times, tt.night)
+		}
+		if n, times := count(runs, "hourly"); n != tt.hourly {
+			t.Errorf("%s: hourly ran %d times %v, want %d", tt.name, n, times, tt.hourly)
+		}
+		if _, times := count(runs, "morning"); len(times) != 1 || times[0] != tt.morningUTC {
+			t.Errorf("%s: 09:00 ran at %v, want %s", tt.name, times, tt.morningUTC)
+		}
+	}
+}
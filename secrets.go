@@ -0,0 +1,481 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// Token stores (config.json "tokenStore"). Tokens from tokenCommand or
+// tokenEnv are never written anywhere.
+const (
+	tokenStorePlain   = "plain"
+	tokenStoreKeyring = "keyring"
+	tokenStoreFile    = "file"
+
+	secretsFileName = "secrets.enc"
+	passphraseEnv   = "SLACK_STATUS_PASSPHRASE"
+	keyringService  = "slack-status-cli"
+	// defaultSecretSlot holds slackToken; workspaces use their name.
+	defaultSecretSlot = "default"
+)
+
+// storedSecret is what a store keeps per workspace.
+type storedSecret struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+}
+
+// secretStore keeps the tokens of one config.json outside of it.
+type secretStore interface {
+	load() (map[string]storedSecret, error)
+	save(map[string]storedSecret) error
+	clear() error
+}
+
+func secretStoreFor(kind, cfgPath string) (secretStore, error) {
+	switch kind {
+	case tokenStoreKeyring:
+		if !keyringAvailable() {
+			return nil, errors.New("tokenStore keyring: no Secret Service (secret-tool) or macOS keychain available")
+		}
+		abs, err := filepath.Abs(cfgPath)
+		if err != nil {
+			return nil, err
+		}
+		return keyringStore{account: abs}, nil
+	case tokenStoreFile:
+		return fileStore{path: filepath.Join(filepath.Dir(cfgPath), secretsFileName)}, nil
+	}
+	return nil, fmt.Errorf("unknown tokenStore %q (use %s, %s or %s)", kind, tokenStoreKeyring, tokenStoreFile, tokenStorePlain)
+}
+
+// defaultTokenStore is used for configs without tokenStore: the keyring if
+// there is one, the encrypted file if a passphrase is set, else plain.
+func defaultTokenStore() string {
+	if keyringAvailable() {
+		return tokenStoreKeyring
+	}
+	if os.Getenv(passphraseEnv) != "" {
+		return tokenStoreFile
+	}
+	return tokenStorePlain
+}
+
+// ── Resolving and writing ────────────────────────────────────────────────────
+
+// resolveSecrets fills in the tokens config.json doesn't hold: from the store,
+// then tokenCommand or tokenEnv, which win over anything stored.
+func (cfg *config) resolveSecrets(cfgPath string) error {
+	if cfg.TokenStore != "" && cfg.TokenStore != tokenStorePlain {
+		store, err := secretStoreFor(cfg.TokenStore, cfgPath)
+		if err != nil {
+			return err
+		}
+		secrets, err := store.load()
+		if err != nil {
+			return fmt.Errorf("tokenStore %s: %w", cfg.TokenStore, err)
+		}
+		if s, ok := secrets[defaultSecretSlot]; ok && cfg.SlackToken == "" {
+			cfg.SlackToken, cfg.RefreshToken = s.Token, s.RefreshToken
+		}
+		for i := range cfg.Workspaces {
+			wc := &cfg.Workspaces[i]
+			if s, ok := secrets[wc.Name]; ok && wc.Token == "" {
+				wc.Token, wc.RefreshToken = s.Token, s.RefreshToken
+			}
+		}
+	}
+	var err error
+	if cfg.SlackToken, err = externalToken(cfg.SlackToken, cfg.TokenCommand, cfg.TokenEnv); err != nil {
+		return err
+	}
+	for i := range cfg.Workspaces {
+		wc := &cfg.Workspaces[i]
+		if wc.Token, err = externalToken(wc.Token, wc.TokenCommand, wc.TokenEnv); err != nil {
+			return fmt.Errorf("workspace %q: %w", wc.Name, err)
+		}
+	}
+	return nil
+}
+
+// externalToken runs tokenCommand or reads tokenEnv; without either the
+// token is kept.
+func externalToken(token, command, env string) (string, error) {
+	switch {
+	case command != "":
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", command)
+		} else {
+			cmd = exec.Command("sh", "-c", command)
+		}
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("tokenCommand: %w %s", err, strings.TrimSpace(stderr.String()))
+		}
+		// pass and similar tools print the secret on the first line.
+		first, _, _ := strings.Cut(string(out), "\n")
+		return strings.TrimSpace(first), nil
+	case env != "":
+		v := strings.TrimSpace(os.Getenv(env))
+		if v == "" {
+			return "", fmt.Errorf("tokenEnv: $%s is not set", env)
+		}
+		return v, nil
+	}
+	return token, nil
+}
+
+// splitSecrets moves the tokens of cfg into the store and returns the config
+// to write to config.json. Tokens from tokenCommand or tokenEnv are dropped.
+func splitSecrets(cfg config, cfgPath string) (config, error) {
+	// A default of plain is not written, so the tokens move once a keyring
+	// shows up.
+	if cfg.TokenStore == "" && defaultTokenStore() != tokenStorePlain {
+		cfg.TokenStore = defaultTokenStore()
+	}
+	external := func(command, env string) bool { return command != "" || env != "" }
+	cfg.Workspaces = append([]workspaceConfig{}, cfg.Workspaces...)
+	if external(cfg.TokenCommand, cfg.TokenEnv) {
+		cfg.SlackToken = ""
+	}
+	for i := range cfg.Workspaces {
+		if wc := &cfg.Workspaces[i]; external(wc.TokenCommand, wc.TokenEnv) {
+			wc.Token = ""
+		}
+	}
+	if cfg.TokenStore == "" || cfg.TokenStore == tokenStorePlain {
+		return cfg, nil
+	}
+
+	secrets := map[string]storedSecret{}
+	if cfg.SlackToken != "" {
+		secrets[defaultSecretSlot] = storedSecret{cfg.SlackToken, cfg.RefreshToken}
+		cfg.SlackToken, cfg.RefreshToken = "", ""
+	}
+	for i := range cfg.Workspaces {
+		wc := &cfg.Workspaces[i]
+		if wc.Token != "" {
+			secrets[wc.Name] = storedSecret{wc.Token, wc.RefreshToken}
+			wc.Token, wc.RefreshToken = "", ""
+		}
+	}
+	store, err := secretStoreFor(cfg.TokenStore, cfgPath)
+	if err != nil {
+		return config{}, err
+	}
+	if err := store.save(secrets); err != nil {
+		return config{}, fmt.Errorf("tokenStore %s: %w", cfg.TokenStore, err)
+	}
+	return cfg, nil
+}
+
+// tokenStoreLabel describes where the primary token comes from.
+func tokenStoreLabel(cfg config) string {
+	switch {
+	case cfg.TokenCommand != "":
+		return "command (" + cfg.TokenCommand + ")"
+	case cfg.TokenEnv != "":
+		return "$" + cfg.TokenEnv
+	case cfg.TokenStore == tokenStoreFile:
+		return "encrypted file (" + secretsFileName + ")"
+	case cfg.TokenStore == tokenStoreKeyring:
+		return "keyring"
+	case cfg.TokenStore == "":
+		return defaultTokenStore() + " (on next save)"
+	}
+	return "config.json (plain text)"
+}
+
+// hasPlainTokens reports whether config.json itself holds a token.
+func hasPlainTokens(cfg config) bool {
+	if cfg.SlackToken != "" && cfg.TokenCommand == "" && cfg.TokenEnv == "" {
+		return true
+	}
+	for _, wc := range cfg.Workspaces {
+		if wc.Token != "" && wc.TokenCommand == "" && wc.TokenEnv == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// migrateSecrets moves the tokens of a config.json written before token
+// stores existed into the default store. It returns a note for the user, or
+// "" if nothing changed.
+func migrateSecrets(path string, raw config) (string, error) {
+	if raw.TokenStore != "" || !hasPlainTokens(raw) {
+		return "", nil
+	}
+	store := defaultTokenStore()
+	if store == tokenStorePlain {
+		// Nowhere safer to put it; at least keep other users out.
+		if err := os.Chmod(path, 0o600); err != nil {
+			return "", err
+		}
+		return "", nil
+	}
+	raw.TokenStore = store
+	if err := writeConfig(path, raw); err != nil {
+		return "", fmt.Errorf("moving token to %s: %w", store, err)
+	}
+	if store == tokenStoreFile {
+		return "Token moved from config.json to " + secretsFileName, nil
+	}
+	return "Token moved from config.json to the keyring", nil
+}
+
+// ── Keyring (Secret Service via secret-tool, macOS keychain) ─────────────────
+
+type keyringStore struct{ account string }
+
+func keyringAvailable() bool {
+	switch runtime.GOOS {
+	case "darwin":
+		_, err := exec.LookPath("security")
+		return err == nil
+	case "windows":
+		return false
+	}
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return false
+	}
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+func (k keyringStore) load() (map[string]storedSecret, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command("security", "find-generic-password", "-s", keyringService, "-a", k.account, "-w")
+	} else {
+		cmd = exec.Command("secret-tool", "lookup", "service", keyringService, "account", k.account)
+	}
+	out, err := cmd.Output()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && keyringNotFound(exit) {
+		return map[string]storedSecret{}, nil
+	}
+	if err != nil {
+		// A locked keyring or a broken D-Bus must not look like a missing
+		// token: the login that follows would overwrite the entry.
+		if exit != nil {
+			return nil, fmt.Errorf("%w %s", err, strings.TrimSpace(string(exit.Stderr)))
+		}
+		return nil, err
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return map[string]storedSecret{}, nil
+	}
+	var secrets map[string]storedSecret
+	if err := json.Unmarshal(bytes.TrimSpace(out), &secrets); err != nil {
+		return nil, fmt.Errorf("keyring entry: %w", err)
+	}
+	return secrets, nil
+}
+
+func (k keyringStore) save(secrets map[string]storedSecret) error {
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		// Through stdin, as hex (-X): arguments are visible in ps.
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -X %x\n", keyringService, securityQuote(k.account), data))
+	} else {
+		cmd = exec.Command("secret-tool", "store", "--label", "Slack status ("+k.account+")", "service", keyringService, "account", k.account)
+		cmd.Stdin = bytes.NewReader(data)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// keyringNotFound tells a lookup without entry from a failing keyring:
+// security exits with 44 (errSecItemNotFound), secret-tool with 1 and
+// nothing on stderr.
+func keyringNotFound(exit *exec.ExitError) bool {
+	if runtime.GOOS == "darwin" {
+		return exit.ExitCode() == 44
+	}
+	return exit.ExitCode() == 1 && len(bytes.TrimSpace(exit.Stderr)) == 0
+}
+
+// securityQuote quotes an argument for a command line of security -i.
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (k keyringStore) clear() error {
+	if runtime.GOOS == "darwin" {
+		return exec.Command("security", "delete-generic-password", "-s", keyringService, "-a", k.account).Run()
+	}
+	return exec.Command("secret-tool", "clear", "service", keyringService, "account", k.account).Run()
+}
+
+// ── Encrypted file ───────────────────────────────────────────────────────────
+
+// fileStore keeps the tokens in secrets.enc, AES-256-GCM with a key derived
+// from a passphrase (PBKDF2-SHA256).
+type fileStore struct{ path string }
+
+const secretsKDFIterations = 600_000
+
+type encryptedSecrets struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+func (f fileStore) load() (map[string]storedSecret, error) {
+	raw, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	var enc encryptedSecrets
+	if err := json.Unmarshal(raw, &enc); err != nil {
+		return nil, fmt.Errorf("%s: %w", secretsFileName, err)
+	}
+	pass, err := passphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := secretsCipher(pass, enc.Salt, enc.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, enc.Nonce, enc.Data, nil)
+	if err != nil {
+		forgetPassphrase()
+		return nil, errors.New("wrong passphrase or damaged " + secretsFileName)
+	}
+	var secrets map[string]storedSecret
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("%s: %w", secretsFileName, err)
+	}
+	return secrets, nil
+}
+
+func (f fileStore) save(secrets map[string]storedSecret) error {
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	_, statErr := os.Stat(f.path)
+	pass, err := passphrase(errors.Is(statErr, os.ErrNotExist))
+	if err != nil {
+		return err
+	}
+	enc := encryptedSecrets{Version: 1, Iterations: secretsKDFIterations, Salt: make([]byte, 16)}
+	if _, err := rand.Read(enc.Salt); err != nil {
+		return err
+	}
+	gcm, err := secretsCipher(pass, enc.Salt, enc.Iterations)
+	if err != nil {
+		return err
+	}
+	enc.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(enc.Nonce); err != nil {
+		return err
+	}
+	enc.Data = gcm.Seal(nil, enc.Nonce, plain, nil)
+	data, err := json.MarshalIndent(enc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.path, data, 0o600)
+}
+
+func (f fileStore) clear() error {
+	if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func secretsCipher(pass string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, pass, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// The passphrase is asked once per run: from $SLACK_STATUS_PASSPHRASE or on
+// the terminal before the TUI starts, and kept for later token refreshes.
+var (
+	passphraseMu     sync.Mutex
+	cachedPassphrase string
+)
+
+func passphrase(confirm bool) (string, error) {
+	passphraseMu.Lock()
+	defer passphraseMu.Unlock()
+	if cachedPassphrase != "" {
+		return cachedPassphrase, nil
+	}
+	if v := os.Getenv(passphraseEnv); v != "" {
+		cachedPassphrase = v
+		return v, nil
+	}
+	// The TUI owns the terminal once it runs.
+	if teaProgram != nil || !term.IsTerminal(os.Stdin.Fd()) {
+		return "", fmt.Errorf("the token file needs a passphrase: set $%s", passphraseEnv)
+	}
+	read := func(prompt string) (string, error) {
+		fmt.Fprint(os.Stderr, prompt)
+		b, err := term.ReadPassword(os.Stdin.Fd())
+		fmt.Fprintln(os.Stderr)
+		return string(b), err
+	}
+	pass, err := read("Passphrase for " + secretsFileName + ": ")
+	if err != nil {
+		return "", err
+	}
+	if pass == "" {
+		return "", errors.New("empty passphrase")
+	}
+	if confirm {
+		again, err := read("Repeat passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != pass {
+			return "", errors.New("passphrases don't match")
+		}
+	}
+	cachedPassphrase = pass
+	return pass, nil
+}
+
+func forgetPassphrase() {
+	passphraseMu.Lock()
+	cachedPassphrase = ""
+	passphraseMu.Unlock()
+}
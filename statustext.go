@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	texttemplate "text/template"
+	"text/template/parse"
+	"time"
+	"unicode/utf8"
+)
+
+// slackStatusTextLimit is the maximum status_text length Slack accepts.
+const slackStatusTextLimit = 100
+
+var germanWeekdays = [...]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"}
+
+// statusVars is the data available to {{...}} placeholders in status texts.
+type statusVars struct {
+	now         time.Time
+	expiration  time.Time
+	nextMeeting time.Time
+	user        string
+}
+
+// textVars collects the model-side values for placeholder rendering; the
+// expiry is filled in when the status is applied.
+func (m model) textVars() statusVars {
+	v := statusVars{user: m.status.User}
+	if m.calSyncEnabled {
+		if t, err := meetingExpiry(untilNextMeeting, true, m.calSync.events, time.Now()); err == nil {
+			v.nextMeeting = t
+		}
+	}
+	return v
+}
+
+func (v statusVars) Date() string    { return v.now.Format("02.01.2006") }
+func (v statusVars) Weekday() string { return germanWeekdays[v.now.Weekday()] }
+func (v statusVars) Time() string    { return v.now.Format("15:04") }
+func (v statusVars) Name() string    { return v.user }
+
+func (v statusVars) Until() (string, error) {
+	if v.expiration.IsZero() {
+		return "", errors.New("{{.Until}} needs a duration or until time")
+	}
+	return v.expiration.Format("15:04"), nil
+}
+
+func (v statusVars) UntilDate() (string, error) {
+	if v.expiration.IsZero() {
+		return "", errors.New("{{.UntilDate}} needs a duration or until time")
+	}
+	return v.expiration.Format("02.01.2006"), nil
+}
+
+func (v statusVars) NextMeeting() (string, error) {
+	if v.nextMeeting.IsZero() {
+		return "", errors.New("{{.NextMeeting}}: no upcoming meeting in calendar")
+	}
+	return v.nextMeeting.Format("15:04"), nil
+}
+
+func (v statusVars) Env(name string) (string, error) {
+	val, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("{{.Env %q}}: variable not set", name)
+	}
+	return val, nil
+}
+
+// renderStatusText resolves placeholders in text. Unknown variables and
+// results longer than Slack's limit are reported as errors.
+func renderStatusText(text string, v statusVars) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return checkStatusTextLength(text)
+	}
+	tmpl, err := texttemplate.New("status").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("status text: %w", err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, v); err != nil {
+		return "", fmt.Errorf("status text: %w", err)
+	}
+	return checkStatusTextLength(strings.TrimSpace(b.String()))
+}
+
+func checkStatusTextLength(text string) (string, error) {
+	if n := utf8.RuneCountInString(text); n > slackStatusTextLimit {
+		return "", fmt.Errorf("status text is %d characters, Slack allows %d", n, slackStatusTextLimit)
+	}
+	return text, nil
+}
+
+// validateStatusText checks a template text when it is saved or loaded:
+// syntax errors and placeholders that aren't methods of statusVars are
+// reported. Values aren't looked at, so {{.Env "X"}} passes even if X is unset.
+func validateStatusText(text string) error {
+	if !strings.Contains(text, "{{") {
+		return nil
+	}
+	tmpl, err := texttemplate.New("status").Parse(text)
+	if err != nil {
+		return fmt.Errorf("status text: %w", err)
+	}
+	if err := checkStatusVars(tmpl.Tree.Root); err != nil {
+		return fmt.Errorf("status text: %w", err)
+	}
+	return nil
+}
+
+var statusVarsType = reflect.TypeOf(statusVars{})
+
+// checkStatusVars walks a parsed status text and reports fields that
+// statusVars doesn't have.
+func checkStatusVars(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Nodes {
+			if err := checkStatusVars(c); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkStatusVars(n.Pipe)
+	case *parse.IfNode:
+		return checkStatusBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		return checkStatusBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return checkStatusBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		return checkStatusVars(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Cmds {
+			if err := checkStatusVars(c); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			if err := checkStatusVars(a); err != nil {
+				return err
+			}
+		}
+	case *parse.ChainNode:
+		return checkStatusVars(n.Node)
+	case *parse.FieldNode:
+		if _, ok := statusVarsType.MethodByName(n.Ident[0]); !ok || len(n.Ident) > 1 {
+			return fmt.Errorf("unknown variable {{%s}}", n)
+		}
+	}
+	return nil
+}
+
+func checkStatusBranch(n *parse.BranchNode) error {
+	for _, c := range []parse.Node{n.Pipe, n.List, n.ElseList} {
+		if err := checkStatusVars(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const favoritesSection = "★ Favoriten"
+
+// templateSection is the list section a template is shown in: the favorites
+// section for pinned templates, otherwise its group ("" = ungrouped).
+func templateSection(t template) string {
+	if t.Favorite {
+		return favoritesSection
+	}
+	return t.Group
+}
+
+// groupHeaderItem is a non-selectable-looking section header in the template list.
+type groupHeaderItem struct {
+	Name      string
+	Count     int
+	Collapsed bool
+}
+
+func (g groupHeaderItem) Title() string       { return g.Name }
+func (g groupHeaderItem) Description() string { return "" }
+func (g groupHeaderItem) FilterValue() string { return "" }
+
+// templateListItems orders templates into sections: favorites first, then
+// ungrouped templates, then each group in order of first appearance. Items of
+// collapsed sections are replaced by their header only.
+func templateListItems(templates []template, collapsed map[string]bool) []list.Item {
+	var order []string
+	bySection := map[string][]template{}
+	for _, t := range templates {
+		sec := templateSection(t)
+		if _, ok := bySection[sec]; !ok {
+			order = append(order, sec)
+		}
+		bySection[sec] = append(bySection[sec], t)
+	}
+
+	sorted := make([]string, 0, len(order))
+	if _, ok := bySection[favoritesSection]; ok {
+		sorted = append(sorted, favoritesSection)
+	}
+	if _, ok := bySection[""]; ok {
+		sorted = append(sorted, "")
+	}
+	for _, sec := range order {
+		if sec != favoritesSection && sec != "" {
+			sorted = append(sorted, sec)
+		}
+	}
+
+	items := make([]list.Item, 0, len(templates)+len(sorted))
+	for _, sec := range sorted {
+		if sec != "" {
+			items = append(items, groupHeaderItem{Name: sec, Count: len(bySection[sec]), Collapsed: collapsed[sec]})
+			if collapsed[sec] {
+				continue
+			}
+		}
+		for _, t := range bySection[sec] {
+			items = append(items, templateItem(t))
+		}
+	}
+	return items
+}
+
+// moveTemplate swaps the template with the given id with its neighbour in the
+// same section (dir -1 = up, +1 = down). The file order of the other
+// templates is kept.
+func moveTemplate(templates []template, id string, dir int) ([]template, bool) {
+	idx := -1
+	for i, t := range templates {
+		if t.ID == id {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return templates, false
+	}
+	sec := templateSection(templates[idx])
+	for j := idx + dir; j >= 0 && j < len(templates); j += dir {
+		if templateSection(templates[j]) != sec {
+			continue
+		}
+		out := append([]template{}, templates...)
+		out[idx], out[j] = out[j], out[idx]
+		return out, true
+	}
+	return templates, false
+}
+
+// templateDelegate renders group headers itself and everything else with the
+// default delegate.
+type templateDelegate struct {
+	list.DefaultDelegate
+}
+
+func newTemplateListDelegate() templateDelegate {
+	return templateDelegate{DefaultDelegate: newTemplateDelegate()}
+}
+
+func (d templateDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	h, ok := item.(groupHeaderItem)
+	if !ok {
+		d.DefaultDelegate.Render(w, m, index, item)
+		return
+	}
+	arrow := "▾"
+	if h.Collapsed {
+		arrow = "▸"
+	}
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#7dc4e4")).Bold(true)
+	if index == m.Index() {
+		style = style.Foreground(lipgloss.Color("#eed49f"))
+	}
+	title := style.PaddingLeft(2).Render(fmt.Sprintf("%s %s (%d)", arrow, h.Name, h.Count))
+	rule := lipgloss.NewStyle().Foreground(lipgloss.Color("#494d64")).PaddingLeft(2).Render("──────────")
+	fmt.Fprint(w, title+"\n"+rule)
+}
+
+// reservedDashboardKeys are taken by built-in dashboard actions and list
+// navigation; templates can't use them as hotkeys.
+var reservedDashboardKeys = map[string]string{
+	"q": "quit", "ctrl+c": "quit", "r": "refresh", "enter": "apply", " ": "toggle group",
+	"a": "manual status", "n": "manual status", "e": "edit current", "c": "create template",
+	"E": "edit template", "f": "favorite", "m": "move to group", "J": "move down", "K": "move up",
+	"x": "delete", "delete": "delete", "backspace": "delete", "s": "settings", "C": "cal-sync", "L": "set later", "V": "vacation", "D": "snooze", "?": "help",
+	"up": "navigation", "down": "navigation", "left": "navigation", "right": "navigation",
+	"pgup": "navigation", "pgdown": "navigation", "home": "navigation", "end": "navigation", "/": "navigation",
+}
+
+// dashboardListKeys is the template list's navigation without the vim-style
+// letters of the default KeyMap, which stay free for template hotkeys.
+func dashboardListKeys() list.KeyMap {
+	keys := list.DefaultKeyMap()
+	keys.CursorUp = key.NewBinding(key.WithKeys("up"), key.WithHelp("↑", "up"))
+	keys.CursorDown = key.NewBinding(key.WithKeys("down"), key.WithHelp("↓", "down"))
+	keys.PrevPage = key.NewBinding(key.WithKeys("left", "pgup"), key.WithHelp("←/pgup", "prev page"))
+	keys.NextPage = key.NewBinding(key.WithKeys("right", "pgdown"), key.WithHelp("→/pgdn", "next page"))
+	keys.GoToStart = key.NewBinding(key.WithKeys("home"), key.WithHelp("home", "go to start"))
+	keys.GoToEnd = key.NewBinding(key.WithKeys("end"), key.WithHelp("end", "go to end"))
+	return keys
+}
+
+// templateForKey returns the template bound to the given hotkey. Reserved keys
+// and later duplicates never match, consistent with validateTemplates.
+func templateForKey(templates []template, key string) (template, bool) {
+	if _, reserved := reservedDashboardKeys[key]; reserved {
+		return template{}, false
+	}
+	for _, t := range templates {
+		if t.Key == key {
+			return t, true
+		}
+	}
+	return template{}, false
+}
+
+// validateTemplates reports hotkeys that clash with built-in keys or are used
+// by more than one template, invalid status texts, unknown weekdays in
+// untilTimeByWeekday and then references to missing templates.
+func validateTemplates(templates []template) error {
+	var problems []string
+	owner := map[string]string{}
+	for _, t := range templates {
+		if err := validateStatusText(t.Text); err != nil {
+			problems = append(problems, fmt.Sprintf("%q: %v", t.Label, err))
+		}
+		for day := range t.UntilTimeByWeekday {
+			if _, ok := weekdayKeys[strings.ToLower(day)]; !ok {
+				problems = append(problems, fmt.Sprintf("%q: unknown weekday %q in untilTimeByWeekday", t.Label, day))
+			}
+		}
+		if t.Then != "" && !strings.EqualFold(t.Then, thenPrevious) {
+			if _, ok := findTemplate(templates, t.Then); !ok {
+				problems = append(problems, fmt.Sprintf("%q: then template %q not found", t.Label, t.Then))
+			}
+		}
+		if t.Key == "" {
+			continue
+		}
+		if action, ok := reservedDashboardKeys[t.Key]; ok {
+			problems = append(problems, fmt.Sprintf("%q: key %q is reserved for %s", t.Label, t.Key, action))
+			continue
+		}
+		if prev, ok := owner[t.Key]; ok {
+			problems = append(problems, fmt.Sprintf("%q: key %q already used by %q", t.Label, t.Key, prev))
+			continue
+		}
+		owner[t.Key] = t.Label
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New("templates.json: " + strings.Join(problems, "; "))
+}
+
+// weekdayKeys maps the accepted untilTimeByWeekday keys (English and German,
+// short and long) to weekdays.
+var weekdayKeys = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday, "so": time.Sunday, "sonntag": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday, "mo": time.Monday, "montag": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday, "di": time.Tuesday, "dienstag": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday, "mi": time.Wednesday, "mittwoch": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday, "do": time.Thursday, "donnerstag": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday, "fr": time.Friday, "freitag": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday, "sa": time.Saturday, "samstag": time.Saturday,
+}
+
+var weekdayShortNames = [...]string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// effectiveUntilTime returns the untilTime that applies on day: the holiday
+// override, then the weekday entry, then the plain untilTime.
+func (t template) effectiveUntilTime(day time.Time) string {
+	if t.UntilTimeHoliday != "" && isHoliday(day) {
+		return t.UntilTimeHoliday
+	}
+	for k, v := range t.UntilTimeByWeekday {
+		if wd, ok := weekdayKeys[strings.ToLower(k)]; ok && wd == day.Weekday() {
+			return v
+		}
+	}
+	return t.UntilTime
+}
+
+// formatUntilSpec renders the until fields of t for the template form, e.g.
+// "16:30; fri=12:30; holiday=12:00".
+func formatUntilSpec(t template) string {
+	parts := []string{}
+	if t.UntilTime != "" {
+		parts = append(parts, t.UntilTime)
+	}
+	days := make([]string, 0, len(t.UntilTimeByWeekday))
+	for k := range t.UntilTimeByWeekday {
+		days = append(days, k)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return (weekdayKeys[strings.ToLower(days[i])]+6)%7 < (weekdayKeys[strings.ToLower(days[j])]+6)%7
+	})
+	for _, k := range days {
+		parts = append(parts, k+"="+t.UntilTimeByWeekday[k])
+	}
+	if t.UntilTimeHoliday != "" {
+		parts = append(parts, "holiday="+t.UntilTimeHoliday)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// parseUntilSpec is the inverse of formatUntilSpec.
+func parseUntilSpec(spec string) (until string, byDay map[string]string, holiday string, err error) {
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			if until != "" {
+				return "", nil, "", fmt.Errorf("until: more than one default time in %q", spec)
+			}
+			until = part
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if key == "holiday" || key == "feiertag" {
+			holiday = value
+			continue
+		}
+		wd, ok := weekdayKeys[key]
+		if !ok {
+			return "", nil, "", fmt.Errorf("until: unknown weekday %q", key)
+		}
+		if byDay == nil {
+			byDay = map[string]string{}
+		}
+		byDay[weekdayShortNames[wd]] = value
+	}
+	return until, byDay, holiday, nil
+}
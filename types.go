@@ -2,8 +2,6 @@ package main
 
 import (
 	"time"
-
-	"github.com/slack-go/slack"
 )
 
 type viewState int
@@ -18,21 +16,47 @@ const (
 	viewDurationSelector
 	viewDurationValue
 	viewCalSyncStatus
+	viewEditTemplate
+	viewMoveTemplateGroup
+	viewQueue
+	viewSetLater
+	viewVacation
+	viewSnooze
+	viewEmojiPicker
 )
 
 const (
 	appName       = "Slack Status TUI"
 	configName    = "config.json"
 	templatesName = "templates.json"
+	followUpsName = "status-followups.json"
 )
 
 type template struct {
+	ID                  string `json:"id,omitempty"`
 	Label               string `json:"label"`
 	Text                string `json:"text"`
 	Emoji               string `json:"emoji"`
 	DurationInMinutes   *int   `json:"durationInMinutes,omitempty"`
 	UntilTime           string `json:"untilTime,omitempty"`
+	UntilMeeting        string `json:"untilMeeting,omitempty"`
 	UseDurationSelector bool   `json:"useDurationSelector,omitempty"`
+	Group               string `json:"group,omitempty"`
+	Favorite            bool   `json:"favorite,omitempty"`
+	Key                 string `json:"key,omitempty"`
+	// Then is applied when this status expires: another template's label or
+	// id, or "previous" for the status that was active before.
+	Then string `json:"then,omitempty"`
+	// UntilTimeByWeekday overrides UntilTime per weekday ("mon".."sun"),
+	// UntilTimeHoliday on configured holidays.
+	UntilTimeByWeekday map[string]string `json:"untilTimeByWeekday,omitempty"`
+	UntilTimeHoliday   string            `json:"untilTimeHoliday,omitempty"`
+	// Presence ("auto" or "away") is set together with the status.
+	Presence string `json:"presence,omitempty"`
+	// DND snoozes notifications for the duration of the status.
+	DND bool `json:"dnd,omitempty"`
+	// Workspaces limits the template to these workspace names (empty = all).
+	Workspaces []string `json:"workspaces,omitempty"`
 }
 
 type templatePayload struct {
@@ -40,15 +64,89 @@ type templatePayload struct {
 }
 
 type config struct {
-	SlackToken    string `json:"slackToken"`
-	ConfirmDelete *bool  `json:"confirmDelete,omitempty"`
+	SlackToken string `json:"slackToken,omitempty"`
+	// RefreshToken and TokenExpiresAt are set for rotating tokens from the
+	// browser login; the token is renewed before it expires.
+	RefreshToken   string `json:"refreshToken,omitempty"`
+	TokenExpiresAt int64  `json:"tokenExpiresAt,omitempty"`
+	// TokenStore keeps the tokens out of config.json: "keyring", "file"
+	// (secrets.enc) or "plain". TokenCommand or TokenEnv read the token from
+	// a command ("pass show slack") or an environment variable instead.
+	TokenStore   string `json:"tokenStore,omitempty"`
+	TokenCommand string `json:"tokenCommand,omitempty"`
+	TokenEnv     string `json:"tokenEnv,omitempty"`
+	// Workspaces replaces slackToken when statuses go to several workspaces.
+	Workspaces []workspaceConfig `json:"workspaces,omitempty"`
+	// OAuth enables the browser login.
+	OAuth         *oauthConfig `json:"oauth,omitempty"`
+	ConfirmDelete *bool        `json:"confirmDelete,omitempty"`
+	Holidays      []string     `json:"holidays,omitempty"`
+	// HolidayRegion enables the built-in public holidays ("DE" or a state
+	// code such as "BY"); HolidayICS adds the all-day events of ICS files.
+	HolidayRegion string   `json:"holidayRegion,omitempty"`
+	HolidayICS    []string `json:"holidayIcs,omitempty"`
+	// RollOverPastUntil moves an HH:MM until time that has already passed
+	// today to tomorrow instead of rejecting it (default true).
+	RollOverPastUntil *bool `json:"rollOverPastUntil,omitempty"`
+	// WorkingHours per weekday ("mon": "08:00-16:30"); missing days are off.
+	WorkingHours map[string]string `json:"workingHours,omitempty"`
+	// EmojiGlyphs shows emoji shortcodes as glyphs (default true).
+	EmojiGlyphs *bool `json:"emojiGlyphs,omitempty"`
+	// LiveStatus refreshes the status card in the background.
+	LiveStatus *liveStatusConfig `json:"liveStatus,omitempty"`
+}
+
+// liveStatusConfig polls users.profile.get every PollSeconds (default 60,
+// -1 turns polling off); AppToken (xapp-…) additionally listens for
+// user_change events through Socket Mode.
+type liveStatusConfig struct {
+	PollSeconds int    `json:"pollSeconds,omitempty"`
+	AppToken    string `json:"appToken,omitempty"`
+}
+
+// workspaceConfig is one entry of config.json's workspaces.
+type workspaceConfig struct {
+	Name  string `json:"name"`
+	Token string `json:"token"`
+	// CalSync sets the meeting status in this workspace (default true).
+	CalSync        *bool  `json:"calSync,omitempty"`
+	RefreshToken   string `json:"refreshToken,omitempty"`
+	TokenExpiresAt int64  `json:"tokenExpiresAt,omitempty"`
+	TokenCommand   string `json:"tokenCommand,omitempty"`
+	TokenEnv       string `json:"tokenEnv,omitempty"`
+}
+
+// oauthConfig is config.json's "oauth": the Slack app used for the browser
+// login. AuthorizeURL and APIURL default to Slack and can point to a local
+// stand-in for testing.
+type oauthConfig struct {
+	ClientID     string   `json:"clientId"`
+	ClientSecret string   `json:"clientSecret"`
+	UserScopes   []string `json:"userScopes,omitempty"`
+	RedirectURL  string   `json:"redirectUrl,omitempty"`
+	AuthorizeURL string   `json:"authorizeUrl,omitempty"`
+	APIURL       string   `json:"apiUrl,omitempty"`
+}
+
+// credentials is a token to store for a workspace ("" = the primary one).
+type credentials struct {
+	Workspace    string
+	Token        string
+	RefreshToken string
+	ExpiresAt    int64
 }
 
 type statusInfo struct {
-	User       string
-	Text       string
-	Emoji      string
-	Expiration string
+	Workspace      string
+	User           string
+	Text           string
+	Emoji          string
+	Expiration     string
+	ExpirationUnix int64
+	// Presence is shown as Slack reports it; ManualAway tells a user-set
+	// away from idle auto-away.
+	Presence   string
+	ManualAway bool
 }
 
 type durationUnit int
@@ -58,19 +156,155 @@ const (
 	durationHours
 	durationMinutes
 	durationNextMonday
+	durationNextMeeting
+	durationMeetingEnd
+	durationCustom
+	durationNextWorkday
+	durationEndOfWorkday
+	durationWorkdays
+)
+
+// untilMeeting values for templates and the manual form's until field.
+const (
+	untilNextMeeting = "nextMeeting"
+	untilMeetingEnd  = "meetingEnd"
 )
 
 type statusMsg statusInfo
 type templatesMsg []template
 type setStatusMsg string
+type presenceMsg struct {
+	Presence   string
+	ManualAway bool
+}
+
+// dndState is the user's snooze; Known is false until it was fetched.
+type dndState struct {
+	Known     bool
+	Snoozing  bool
+	SnoozeEnd time.Time
+}
+type dndMsg dndState
+
+// statusChange is a status that changed between two fetches; Source is the
+// likely cause (sourceApp, sourceExpiry or sourceElsewhere).
+type statusChange struct {
+	Workspace string
+	Old, New  statusInfo
+	Source    string
+	At        time.Time
+}
+
+// customEmojiMsg carries the custom emoji of the workspaces (emoji.list).
+type customEmojiMsg struct {
+	Cache emojiCache
+	Err   error
+}
+
+// liveStatusMsg is a status fetched in the background; unlike statusMsg it
+// leaves the message line alone.
+type liveStatusMsg statusInfo
+type livePollMsg struct{}
+
+// liveEventMsg reports a user_change event of the user from Socket Mode.
+type liveEventMsg struct{}
+
+// pendingWrite is a status write that failed while Slack couldn't be reached
+// (offline-queue.json); it is replayed in order once Slack answers again.
+type pendingWrite struct {
+	ID         string `json:"id"`
+	Workspace  string `json:"workspace,omitempty"`
+	Text       string `json:"text"`
+	Emoji      string `json:"emoji"`
+	Expiration int64  `json:"expiration,omitempty"`
+	QueuedAt   int64  `json:"queuedAt"`
+	Attempts   int    `json:"attempts,omitempty"`
+	LastError  string `json:"lastError,omitempty"`
+}
+
+// offlineQueueMsg carries the offline queue; Sent counts replayed writes.
+type offlineQueueMsg struct {
+	Items []pendingWrite
+	Sent  int
+	Err   error
+}
+type healthMsg []tokenHealth
 type savedTemplatesMsg []template
 type errMsg struct{ err error }
 
 type configUpdatedMsg struct {
-	cfg    config
-	client *slack.Client
-	msg    string
-	path   string
+	cfg  config
+	msg  string
+	path string
+}
+
+type loginStartedMsg struct{ URL string }
+type loginFailedMsg struct{ err error }
+type tokensRefreshedMsg struct {
+	cfg  config
+	path string
+	err  error
+	// revoked maps workspaces (""= slackToken) to the refresh token Slack
+	// rejected for good; transient is set if any other refresh failed.
+	revoked   map[string]string
+	transient bool
+}
+
+// refreshState keeps failed token refreshes from being retried every tick:
+// revoked refresh tokens wait for a new login, transient failures back off.
+type refreshState struct {
+	revoked  map[string]string
+	failures int
+	retryAt  time.Time
+}
+
+// workspaceResult is the outcome of a broadcast in one workspace.
+type workspaceResult struct {
+	Workspace string
+	Err       error
+}
+type workspaceResultsMsg struct {
+	Action  string
+	Results []workspaceResult
+}
+
+// thenPrevious as a template's then restores the status active before it.
+const thenPrevious = "previous"
+
+// followUp is a queued status change that runs when a chained status expires
+// (status-followups.json).
+type followUp struct {
+	ID string `json:"id"`
+	// At is when the chained status expires (unix seconds).
+	At int64 `json:"at"`
+	// ExpectEmoji is the emoji of the chained status; the follow-up only runs
+	// while that status (or none) is still set.
+	ExpectEmoji string `json:"expectEmoji"`
+	// TemplateID is applied next; if empty, Origin is restored.
+	TemplateID string `json:"templateId,omitempty"`
+	// Origin is the status that was active before the chain started.
+	Origin savedStatus `json:"origin"`
+	Label  string      `json:"label"`
+	// Workspaces of the chained template; "previous" restores only there.
+	Workspaces []string `json:"workspaces,omitempty"`
+}
+
+type clockTickMsg time.Time
+type followUpsSavedMsg struct{ err error }
+
+// followUpStatusMsg is the result of a status write that starts a then-chain;
+// Item is only pushed once the write went through.
+type followUpStatusMsg struct {
+	workspaceResultsMsg
+	Item followUp
+}
+
+// followUpCheckedMsg carries the current status of each target workspace of
+// a due follow-up, keyed by workspace name.
+type followUpCheckedMsg struct {
+	Item    followUp
+	Current map[string]statusInfo
+	Err     error
 }
 
 // Calendar sync config (calendar-sync.json)
@@ -84,6 +318,9 @@ type calSyncConfig struct {
 	StatePath              string `json:"statePath"`
 	Debug                  bool   `json:"debug"`
 	DebugLogPath           string `json:"debugLogPath"`
+	WindowPastDays         int    `json:"windowPastDays"`
+	WindowFutureDays       int    `json:"windowFutureDays"`
+	SnoozeDuringMeetings   bool   `json:"snoozeDuringMeetings"`
 }
 
 type calEvent struct {
@@ -95,10 +332,14 @@ type calEvent struct {
 }
 
 type savedStatus struct {
-	Text              string `json:"text"`
-	Emoji             string `json:"emoji"`
-	ExpirationUnix    int64  `json:"expirationUnix"`
-	SavedAt           int64  `json:"savedAt"`
+	Text           string `json:"text"`
+	Emoji          string `json:"emoji"`
+	ExpirationUnix int64  `json:"expirationUnix"`
+	SavedAt        int64  `json:"savedAt"`
+	Presence       string `json:"presence,omitempty"`
+	// DND state before the meeting; DNDManaged marks snoozes cal-sync started.
+	DNDManaged        bool   `json:"dndManaged,omitempty"`
+	DNDSnoozeEnd      int64  `json:"dndSnoozeEnd,omitempty"`
 	ActiveEventID     string `json:"activeEventId,omitempty"`
 	ActiveEventEndUTC string `json:"activeEventEndUtc,omitempty"`
 }
@@ -111,6 +352,13 @@ type calSyncState struct {
 	StatusSaved     bool
 	StatusSavedText string
 	pendingEvent    *calEvent
+	// Workspaces whose meeting status was changed elsewhere: their status
+	// is kept when the meeting ends.
+	overridden map[string]bool
+	// Feed cache: the last parsed events and the sha256 of the feed they came from.
+	events   []calEvent
+	feedHash string
+	parsedAt time.Time
 }
 
 // Calendar sync tea.Msg types
@@ -118,16 +366,115 @@ type calSyncTickMsg struct{}
 type calEventsMsg struct {
 	Events    []calEvent
 	FetchedAt time.Time
+	Hash      string
+	Unchanged bool
 }
 type calStatusSetMsg struct {
 	EventID     string
 	EventEnd    time.Time
 	StatusText  string
 	StatusEmoji string
+	// Err reports workspaces that failed while others succeeded.
+	Err error
+}
+type calStatusRestoredMsg struct {
+	PreviousText string
+	Err          error
 }
-type calStatusRestoredMsg struct{ PreviousText string }
 type calStatusSavedMsg struct{ Snapshot savedStatus }
 type calSyncErrMsg struct {
 	Err     error
 	IsFatal bool
 }
+
+// scheduleConfig is schedule.json: recurring rules that apply templates.
+type scheduleConfig struct {
+	Enabled *bool `json:"enabled,omitempty"`
+	// CatchUp decides what happens to runs missed during sleep or downtime:
+	// "latest" (default) applies only the most recent one, "all" applies all
+	// in order, "skip" drops them. Runs older than MaxLateMinutes always drop.
+	CatchUp        string         `json:"catchUp,omitempty"`
+	MaxLateMinutes int            `json:"maxLateMinutes,omitempty"`
+	Rules          []scheduleRule `json:"rules"`
+}
+
+// scheduleRule applies Template (id or label) either at At on Days or
+// whenever the five-field Cron expression matches.
+type scheduleRule struct {
+	Template string   `json:"template"`
+	At       string   `json:"at,omitempty"`
+	Days     []string `json:"days,omitempty"`
+	Cron     string   `json:"cron,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       string   `json:"to,omitempty"`
+	Except   []string `json:"except,omitempty"`
+	CatchUp  string   `json:"catchUp,omitempty"`
+	// SkipHolidays suppresses the rule on public and configured holidays.
+	SkipHolidays bool `json:"skipHolidays,omitempty"`
+	// Compiled form, filled by compile.
+	valid    bool
+	cron     *cronSpec
+	atMinute int
+	days     uint8
+}
+
+type scheduledRun struct {
+	Rule     int
+	At       time.Time
+	Template string
+}
+
+type scheduleState struct {
+	enabled   bool
+	cfg       scheduleConfig
+	statePath string
+	lastCheck time.Time
+	upcoming  []scheduledRun
+	// upcomingDay is the date upcoming was scanned on.
+	upcomingDay string
+}
+
+// queuedStatus is a one-off status change planned for At (status-queue.json).
+// It applies a template (by id; Label is kept for display) or a text/emoji
+// pair; Until overrides the expiry. Late decides what happens if At was
+// missed: "apply" or "skip".
+type queuedStatus struct {
+	ID       string `json:"id"`
+	At       int64  `json:"at"`
+	Template string `json:"template,omitempty"`
+	Label    string `json:"label,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Emoji    string `json:"emoji,omitempty"`
+	Until    int64  `json:"until,omitempty"`
+	Late     string `json:"late,omitempty"`
+}
+
+// queueLoadedMsg carries the queue file; Due are the items a clock tick
+// claimed from it, to be applied now.
+type queueLoadedMsg struct {
+	Items []queuedStatus
+	Due   []queuedStatus
+	Err   error
+}
+
+// vacation is a planned absence (vacation.json): Text/Emoji are asserted from
+// Start until Return, then ReturnTemplate (an id; ReturnLabel is kept for
+// display) is applied if set.
+type vacation struct {
+	Start          int64  `json:"start"`
+	Return         int64  `json:"return"`
+	LastDay        string `json:"lastDay"`
+	Text           string `json:"text"`
+	Emoji          string `json:"emoji"`
+	Delegate       string `json:"delegate,omitempty"`
+	ReturnTemplate string `json:"returnTemplate,omitempty"`
+	ReturnLabel    string `json:"returnLabel,omitempty"`
+	DND            bool   `json:"dnd,omitempty"`
+	LastCheck      int64  `json:"lastCheck,omitempty"`
+}
+
+// vacationCheckedMsg carries the status of each workspace, keyed by name.
+type vacationCheckedMsg struct {
+	Current map[string]statusInfo
+	Err     error
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	vacationName         = "vacation.json"
+	defaultVacationText  = "Urlaub"
+	defaultVacationEmoji = ":palm_tree:"
+)
+
+// vacationPathFor keeps the vacation plan next to config.json.
+func vacationPathFor(cfgPath string) string {
+	return filepath.Join(filepath.Dir(configPathForSave(cfgPath)), vacationName)
+}
+
+func loadVacation(path string) (*vacation, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var v vacation
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("%s: %w", vacationName, err)
+	}
+	return &v, nil
+}
+
+func saveVacationCmd(path string, v *vacation) tea.Cmd {
+	var snapshot *vacation
+	if v != nil {
+		c := *v
+		snapshot = &c
+	}
+	return func() tea.Msg {
+		if snapshot == nil {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return errMsg{fmt.Errorf("vacation: %w", err)}
+			}
+			return nil
+		}
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		if err == nil {
+			err = os.WriteFile(path, data, 0o644)
+		}
+		if err != nil {
+			return errMsg{fmt.Errorf("vacation: %w", err)}
+		}
+		return nil
+	}
+}
+
+var vacationDateLayouts = []string{"2006-01-02", "02.01.2006", "2.1.2006"}
+
+// parseVacationDay reads a day as YYYY-MM-DD, DD.MM.YYYY, DD.MM. (next such
+// date) or any expiry expression ("friday", "morgen").
+func parseVacationDay(v string, now time.Time) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	for _, layout := range vacationDateLayouts {
+		if t, err := time.ParseInLocation(layout, v, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.ParseInLocation("2.1.2006", strings.TrimSuffix(v, ".")+"."+fmt.Sprint(now.Year()), now.Location()); err == nil {
+		if t.Before(startOfDay(now)) {
+			t = t.AddDate(1, 0, 0)
+		}
+		return t, nil
+	}
+	t, err := parseExpiryExpr(v, now, true)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date %q: expected e.g. 2026-12-24, 24.12. or friday", v)
+	}
+	return startOfDay(t), nil
+}
+
+// newVacation reads the vacation form. The status runs from the first day
+// (or now, if that is today) until the return, which defaults to the start
+// of the first workday after the last day.
+func newVacation(from, to, back, delegate, text, emoji, returnTemplate, dnd string, now time.Time, templates []template) (vacation, error) {
+	start := startOfDay(now)
+	if strings.TrimSpace(from) != "" {
+		d, err := parseVacationDay(from, now)
+		if err != nil {
+			return vacation{}, fmt.Errorf("from: %w", err)
+		}
+		start = d
+	}
+	if strings.TrimSpace(to) == "" {
+		return vacation{}, errors.New("last day is required")
+	}
+	last, err := parseVacationDay(to, now)
+	if err != nil {
+		return vacation{}, fmt.Errorf("to: %w", err)
+	}
+	if last.Before(start) {
+		return vacation{}, errors.New("last day is before the first day")
+	}
+	ret := workdayStartAfter(last)
+	if strings.TrimSpace(back) != "" {
+		if ret, err = parseVacationDay(back, now); err != nil {
+			return vacation{}, fmt.Errorf("return: %w", err)
+		}
+		if span, ok := workSpanFor(ret); ok {
+			ret = atMinute(ret, span.start)
+		}
+		if !ret.After(last) {
+			return vacation{}, errors.New("return must be after the last day")
+		}
+	}
+	if !ret.After(now) {
+		return vacation{}, errors.New("vacation is already over")
+	}
+	ref, returnLabel := strings.TrimSpace(returnTemplate), ""
+	if ref != "" {
+		t, ok := findTemplate(templates, ref)
+		if !ok {
+			return vacation{}, fmt.Errorf("template %q not found", ref)
+		}
+		if t.UseDurationSelector {
+			return vacation{}, fmt.Errorf("template %q asks for a duration and can't run unattended", t.Label)
+		}
+		ref, returnLabel = t.ID, t.Label
+	}
+	useDND, err := parseYesNo(dnd)
+	if err != nil {
+		return vacation{}, fmt.Errorf("dnd: %w", err)
+	}
+	v := vacation{
+		Start:          start.Unix(),
+		Return:         ret.Unix(),
+		LastDay:        last.Format("2006-01-02"),
+		Delegate:       strings.TrimSpace(delegate),
+		Text:           missing(strings.TrimSpace(text), defaultVacationText),
+		Emoji:          missing(strings.TrimSpace(emoji), defaultVacationEmoji),
+		ReturnTemplate: ref,
+		ReturnLabel:    returnLabel,
+		DND:            useDND,
+	}
+	if err := validateStatusText(v.statusText()); err != nil {
+		return vacation{}, err
+	}
+	return v, nil
+}
+
+// statusText is what Slack shows, e.g. "Urlaub bis 31.10. · Vertretung: @max".
+func (v vacation) statusText() string {
+	last, err := time.Parse("2006-01-02", v.LastDay)
+	if err != nil {
+		last = time.Unix(v.Return, 0).Add(-24 * time.Hour)
+	}
+	s := fmt.Sprintf("%s bis %s", v.Text, last.Format("02.01."))
+	if v.Delegate != "" {
+		s += " · Vertretung: " + v.Delegate
+	}
+	return s
+}
+
+func (v vacation) active(now time.Time) bool {
+	return now.Unix() >= v.Start && now.Unix() < v.Return
+}
+
+// vacationCheckCmd reads the current status in every workspace so the model
+// can tell where something else replaced the vacation status.
+func vacationCheckCmd(targets []workspace) tea.Cmd {
+	return func() tea.Msg {
+		current, err := currentStatuses(targets)
+		return vacationCheckedMsg{Current: current, Err: err}
+	}
+}
+
+// handleVacationTick asserts the vacation status once a day while it runs and
+// switches to the return template when it is over. Like follow-ups it waits
+// while cal-sync shows a meeting.
+func (m model) handleVacationTick(now time.Time) (model, tea.Cmd) {
+	v := m.vacation
+	if v == nil || m.vacationChecking || m.calSync.ActiveEventID != "" {
+		return m, nil
+	}
+	if now.Unix() >= v.Return {
+		return m.endVacation()
+	}
+	if !v.active(now) {
+		return m, nil
+	}
+	if v.LastCheck > 0 && startOfDay(time.Unix(v.LastCheck, 0)).Equal(startOfDay(now)) {
+		return m, nil
+	}
+	m.vacationChecking = true
+	return m, vacationCheckCmd(m.workspaces)
+}
+
+func (m model) handleVacationChecked(msg vacationCheckedMsg) (model, tea.Cmd) {
+	m.vacationChecking = false
+	if m.vacation == nil {
+		return m, nil
+	}
+	if msg.Err != nil {
+		m.err = fmt.Errorf("vacation check: %w", msg.Err)
+		return m, nil
+	}
+	v := *m.vacation
+	v.LastCheck = time.Now().Unix()
+	m.vacation = &v
+	cmds := []tea.Cmd{saveVacationCmd(m.vacationPath, m.vacation)}
+	// Only the workspaces where something else replaced it are set again.
+	var targets []workspace
+	for _, ws := range m.workspaces {
+		if cur := msg.Current[ws.name]; cur.Emoji != v.Emoji || cur.Text != v.statusText() {
+			targets = append(targets, ws)
+		}
+	}
+	if len(targets) > 0 {
+		m.message = "Vacation status set: " + v.statusText()
+		m.followUps = nil
+		cmds = append(cmds,
+			setStatusCmd(targets, v.statusText(), v.Emoji, time.Unix(v.Return, 0), m.textVars()),
+			saveFollowUpsCmd(m.followUpsPath, m.followUps),
+		)
+		if v.DND {
+			cmds = append(cmds, snoozeCmd(targets, time.Unix(v.Return, 0)))
+		}
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m model) endVacation() (model, tea.Cmd) {
+	v := *m.vacation
+	m.vacation = nil
+	cmds := []tea.Cmd{saveVacationCmd(m.vacationPath, nil)}
+	if v.DND {
+		cmds = append(cmds, snoozeCmd(m.workspaces, time.Time{}))
+	}
+	if v.ReturnTemplate != "" {
+		if t, ok := findTemplate(m.templates, v.ReturnTemplate); ok {
+			var cmd tea.Cmd
+			m, cmd = m.applyTemplate(t)
+			m.message = "Welcome back: " + t.Label
+			return m, tea.Batch(append(cmds, cmd)...)
+		}
+		m.err = fmt.Errorf("vacation: return template %q not found", missing(v.ReturnLabel, v.ReturnTemplate))
+	}
+	m.message = "Vacation over, status cleared"
+	return m, tea.Batch(append(cmds, setStatusCmd(m.workspaces, "", "", time.Time{}, m.textVars()))...)
+}
+
+// ── Vacation form ────────────────────────────────────────────────────────────
+
+func (m model) enterVacationForm() model {
+	m.state = viewVacation
+	m.message = "Plan a vacation"
+	if m.vacation != nil {
+		m.message = "Edit vacation (clear first and last day to cancel)"
+	}
+	m.inputs = buildVacationInputs(m.vacation)
+	m.focusIndex = 0
+	return m
+}
+
+func (m model) submitVacationForm() (tea.Model, tea.Cmd) {
+	v := func(i int) string { return m.inputs[i].Value() }
+	now := time.Now()
+	if strings.TrimSpace(v(0)) == "" && strings.TrimSpace(v(1)) == "" && m.vacation != nil {
+		m.state = viewDashboard
+		m.inputs = nil
+		if m.vacation.active(now) {
+			return m.endVacation()
+		}
+		// Not started yet: nothing was set in Slack, so nothing to undo.
+		m.vacation = nil
+		m.message = "Vacation cancelled"
+		return m, saveVacationCmd(m.vacationPath, nil)
+	}
+	plan, err := newVacation(v(0), v(1), v(2), v(3), v(4), v(5), v(6), v(7), now, m.templates)
+	if err != nil {
+		return m.withError(err), nil
+	}
+	m.vacation = &plan
+	m.state = viewDashboard
+	m.inputs = nil
+	m.err = nil
+	m.message = "Vacation planned: " + plan.statusText()
+	cmds := []tea.Cmd{saveVacationCmd(m.vacationPath, m.vacation)}
+	if plan.active(now) {
+		m.vacationChecking = true
+		cmds = append(cmds, vacationCheckCmd(m.workspaces))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// vacationPreview shows the resulting status and return while typing.
+func (m model) vacationPreview() string {
+	v := func(i int) string { return m.inputs[i].Value() }
+	if len(m.inputs) < 8 || strings.TrimSpace(v(1)) == "" {
+		return ""
+	}
+	now := time.Now()
+	plan, err := newVacation(v(0), v(1), v(2), v(3), v(4), v(5), v(6), v(7), now, m.templates)
+	if err != nil {
+		return err.Error()
+	}
+	return renderEmoji(plan.Emoji+" "+plan.statusText()) + " \a back " + formatExpiry(time.Unix(plan.Return, 0), now)
+}
+
+// summary is the status card line for a planned or running vacation.
+func (v vacation) summary(now time.Time) string {
+	prefix := "Vacation"
+	if !v.active(now) {
+		prefix = "Vacation from " + formatExpiry(time.Unix(v.Start, 0), now)
+	}
+	s := fmt.Sprintf("%s: back %s", prefix, formatExpiry(time.Unix(v.Return, 0), now))
+	if v.ReturnTemplate != "" {
+		s += " → " + missing(v.ReturnLabel, v.ReturnTemplate)
+	}
+	if v.DND {
+		s += " (DND)"
+	}
+	return s
+}
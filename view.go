@@ -16,7 +16,7 @@ func (m model) View() string {
 	}
 
 	header := renderHeader()
-	statusCard := renderStatusCard(m.status, m.err, m.calSync, m.calSyncEnabled)
+	statusCard := renderStatusCard(m.status, m.err, m.calSync, m.calSyncEnabled, m.followUps, m.schedule.upcoming, m.queue, m.vacation, m.dnd, m.workspaceLines(), m.offlineQueue, liveChangeLine(m.lastChange, len(m.workspaces) > 1, time.Now()))
 	body := m.renderBody()
 	footer := renderFooter(m.status.User)
 
@@ -28,9 +28,29 @@ func (m model) renderBody() string {
 		return renderCalSyncStatusView(m.calSync, m.calSyncEnabled)
 	}
 
+	if m.state == viewQueue {
+		return renderQueueView(m.queue, m.queueCursor, m.message)
+	}
+
+	if m.state == viewSetLater {
+		return renderForm(m.state, m.inputs, m.setLaterPreview())
+	}
+
+	if m.state == viewVacation {
+		return renderForm(m.state, m.inputs, m.vacationPreview())
+	}
+
+	if m.state == viewSnooze {
+		return renderForm(m.state, m.inputs, m.snoozePreview())
+	}
+
+	if m.state == viewEmojiPicker {
+		return renderEmojiPicker(m.picker, m.workspaces)
+	}
+
 	if m.state == viewDashboard || m.state == viewDeleteConfirm {
 		left := lipgloss.JoinVertical(lipgloss.Left, renderPanelTitle("Templates"), m.templateList.View())
-		help := renderHelp(m.state == viewDeleteConfirm, m.message)
+		help := renderHelp(m.state == viewDeleteConfirm, m.message, m.selectedExpiryPreview())
 		right := lipgloss.JoinVertical(lipgloss.Left, renderPanelTitle("Actions"), help)
 		return lipgloss.JoinHorizontal(lipgloss.Top, left, right)
 	}
@@ -50,7 +70,11 @@ func (m model) renderBody() string {
 		return lipgloss.JoinVertical(lipgloss.Left, renderDurationValueForm(m))
 	}
 
-	form := renderForm(m.state, m.inputs)
+	preview := m.formExpiryPreview()
+	if status := m.formStatusPreview(); status != "" {
+		preview = strings.TrimSpace(status + "\n" + preview)
+	}
+	form := renderForm(m.state, m.inputs, preview)
 	return lipgloss.JoinVertical(lipgloss.Left, form)
 }
 
@@ -60,15 +84,49 @@ func renderHeader() string {
 	return lipgloss.JoinHorizontal(lipgloss.Top, title, sub)
 }
 
-func renderStatusCard(info statusInfo, err error, calSync calSyncState, calEnabled bool) string {
+func renderStatusCard(info statusInfo, err error, calSync calSyncState, calEnabled bool, followUps []followUp, upcoming []scheduledRun, queue []queuedStatus, vac *vacation, dnd dndState, workspaces []string, offline []pendingWrite, change string) string {
 	indicator := renderCalSyncIndicator(calSync, calEnabled)
-	base := fmt.Sprintf("User: %s\nStatus: %s %s\nExpires: %s\n%s",
+	base := fmt.Sprintf("User: %s\nStatus: %s %s\nExpires: %s\nPresence: %s \a DND: %s\n%s",
 		missing(info.User, "unknown"),
-		missing(info.Text, "-"),
-		info.Emoji,
+		missing(renderEmoji(info.Text), "-"),
+		renderEmoji(info.Emoji),
 		missing(info.Expiration, "none"),
+		presenceLabel(info.Presence, info.ManualAway),
+		dndLabel(dnd),
 		indicator,
 	)
+	for _, line := range workspaces {
+		base += "\n  " + line
+	}
+	for i := len(followUps) - 1; i >= 0; i-- {
+		fu := followUps[i]
+		base += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#c6a0f6")).Render(
+			fmt.Sprintf("Then: %s at %s", fu.Label, time.Unix(fu.At, 0).Local().Format("15:04")))
+	}
+	now := time.Now()
+	if name := holidayName(now); name != "" {
+		base += "\nHeute: " + name
+	}
+	if vac != nil {
+		base += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#a6da95")).Render(vac.summary(now))
+	}
+	if len(queue) > 0 {
+		base += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#8aadf4")).Render(
+			fmt.Sprintf("Later: %s (%d queued, L)", queue[0].describe(now), len(queue)))
+	}
+	if change != "" {
+		base += "\n" + change
+	}
+	if line := offlineQueueLine(offline); line != "" {
+		base += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#f5a97f")).Render(line)
+	}
+	for i, run := range upcoming {
+		if i == maxUpcomingShown {
+			break
+		}
+		base += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#8aadf4")).Render(
+			fmt.Sprintf("Scheduled: %s %s", formatExpiry(run.At, now), run.Template))
+	}
 	if err != nil {
 		base += "\n\n" + err.Error()
 	}
@@ -81,11 +139,91 @@ func renderStatusCard(info statusInfo, err error, calSync calSyncState, calEnabl
 	return card
 }
 
+// maxUpcomingShown limits the scheduled runs listed on the status card.
+const maxUpcomingShown = 3
+
 func renderPanelTitle(text string) string {
 	return lipgloss.NewStyle().Foreground(lipgloss.Color("#c6a0f6")).Bold(true).Padding(0, 1).Render(text)
 }
 
-func renderHelp(confirm bool, message string) string {
+// selectedExpiryPreview shows when the selected template would expire if
+// applied now.
+func (m model) selectedExpiryPreview() string {
+	t := m.selectedTemplate()
+	if t == nil || t.UseDurationSelector {
+		return ""
+	}
+	now := time.Now()
+	exp, err := m.templateExpiry(*t, now)
+	if err != nil {
+		return "Expires: " + err.Error()
+	}
+	return "Expires: " + formatExpiry(exp, now)
+}
+
+// formExpiryPreview resolves the duration/until fields of the current form as
+// they are typed.
+func (m model) formExpiryPreview() string {
+	durationIdx, untilIdx := -1, -1
+	switch m.state {
+	case viewManual, viewEditCurrent:
+		durationIdx, untilIdx = 2, 3
+	case viewCreateTemplate, viewEditTemplate:
+		durationIdx, untilIdx = 3, 4
+	}
+	if untilIdx < 0 || len(m.inputs) <= untilIdx {
+		return ""
+	}
+	duration, err := parseOptionalMinutes(m.inputs[durationIdx].Value())
+	if err != nil {
+		return "Expires: invalid duration"
+	}
+	until := strings.TrimSpace(m.inputs[untilIdx].Value())
+	now := time.Now()
+	var exp time.Time
+	if _, meeting := parseUntilMeeting(until); untilIdx == 4 && !meeting {
+		t := template{DurationInMinutes: duration}
+		if t.UntilTime, t.UntilTimeByWeekday, t.UntilTimeHoliday, err = parseUntilSpec(until); err != nil {
+			return "Expires: " + err.Error()
+		}
+		exp, err = m.templateExpiry(t, now)
+	} else {
+		exp, err = m.resolveExpiry(duration, until, now)
+	}
+	if err != nil {
+		return "Expires: " + err.Error()
+	}
+	return "Expires: " + formatExpiry(exp, now)
+}
+
+// formStatusPreview shows the status of the manual and template forms with
+// the emoji as it will look.
+func (m model) formStatusPreview() string {
+	field := emojiFieldIndex(m.state)
+	if field < 1 || len(m.inputs) <= field || m.state == viewSetLater || m.state == viewVacation {
+		return ""
+	}
+	status := strings.TrimSpace(m.inputs[field].Value() + " " + m.inputs[field-1].Value())
+	if status == "" {
+		return ""
+	}
+	return "Shows as: " + renderEmoji(status)
+}
+
+// durationValuePreview resolves the duration selector's value input as typed.
+func (m model) durationValuePreview() string {
+	if len(m.inputs) == 0 || strings.TrimSpace(m.inputs[0].Value()) == "" {
+		return ""
+	}
+	now := time.Now()
+	exp, err := m.durationValueExpiry(now)
+	if err != nil {
+		return "Expires: " + err.Error()
+	}
+	return "Expires: " + formatExpiry(exp, now)
+}
+
+func renderHelp(confirm bool, message, preview string) string {
 	if confirm {
 		return lipgloss.NewStyle().Padding(1, 2).Render("Press y to confirm deletion or any other key to cancel.")
 	}
@@ -94,6 +232,13 @@ func renderHelp(confirm bool, message string) string {
 		"a manual status",
 		"e edit current",
 		"c create template",
+		"E edit template",
+		"f favorite",
+		"J/K move down/up",
+		"m move to group",
+		"L set later",
+		"V vacation",
+		"D snooze on/off",
 		"s settings",
 		"C cal-sync",
 		"x delete template",
@@ -105,6 +250,9 @@ func renderHelp(confirm bool, message string) string {
 	if message != "" {
 		msg = "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#a6da95")).Render(message)
 	}
+	if preview != "" {
+		msg += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#eed49f")).Render(preview)
+	}
 	return lipgloss.NewStyle().Padding(1, 2).Width(40).Render(helpText + msg)
 }
 
@@ -121,12 +269,22 @@ func renderDurationHelp(message string) string {
 	return lipgloss.NewStyle().Padding(1, 2).Width(40).Render(helpText + msg)
 }
 
-func renderForm(state viewState, inputs []textinput.Model) string {
+func renderForm(state viewState, inputs []textinput.Model, preview string) string {
 	title := "Manual Status"
 	if state == viewEditCurrent {
 		title = "Edit Current Status"
 	} else if state == viewCreateTemplate {
 		title = "Create Template"
+	} else if state == viewEditTemplate {
+		title = "Edit Template"
+	} else if state == viewMoveTemplateGroup {
+		title = "Move to Group"
+	} else if state == viewSetLater {
+		title = "Set Later"
+	} else if state == viewVacation {
+		title = "Vacation"
+	} else if state == viewSnooze {
+		title = "Do Not Disturb"
 	}
 	var b strings.Builder
 	b.WriteString(renderPanelTitle(title))
@@ -135,7 +293,15 @@ func renderForm(state viewState, inputs []textinput.Model) string {
 		b.WriteString(input.View())
 		b.WriteString("\n\n")
 	}
-	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#8aadf4")).Render("Enter to submit \a Esc to cancel \a Tab to switch fields"))
+	if preview != "" {
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#eed49f")).Render(preview))
+		b.WriteString("\n\n")
+	}
+	hint := "Enter to submit \a Esc to cancel \a Tab to switch fields"
+	if emojiFieldIndex(state) >= 0 {
+		hint += " \a Ctrl+E emoji"
+	}
+	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#8aadf4")).Render(hint))
 	card := lipgloss.NewStyle().
 		Border(lipgloss.DoubleBorder()).
 		BorderForeground(lipgloss.Color("#c6a0f6")).
@@ -154,6 +320,10 @@ func renderDurationValueForm(m model) string {
 		b.WriteString(input.View())
 		b.WriteString("\n\n")
 	}
+	if preview := m.durationValuePreview(); preview != "" {
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#eed49f")).Render(preview))
+		b.WriteString("\n\n")
+	}
 	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#8aadf4")).Render("Enter to submit \a Esc to cancel"))
 	card := lipgloss.NewStyle().
 		Border(lipgloss.DoubleBorder()).
@@ -183,12 +353,23 @@ func renderSettingsView(m model) string {
 	if m.confirmDelete {
 		confirm = "yes"
 	}
+	user := missing(m.status.User, "unknown")
+	if len(m.workspaces) > 1 {
+		user += " (" + m.workspaces[0].label() + ", token below)"
+	}
+	login := "Ctrl+R shows the token \a Ctrl+O to log in with Slack in the browser"
+	if m.loginURL != "" {
+		login = "Waiting for the browser login. If no browser opened, visit:\n" + m.loginURL
+	}
 	body := fmt.Sprintf(
-		"Logged in as: %s\nConfirm deletions: %s (toggle with t)\n\nConfig path: %s\n\n%s\n\nEnter to save \a Esc to cancel",
-		missing(m.status.User, "unknown"),
+		"Logged in as: %s\nConfirm deletions: %s (toggle with t)\n\nConfig path: %s\nToken store: %s\n\n%s\n\n%s\n%s\n\nEnter to save \a Esc to cancel",
+		user,
 		confirm,
 		m.configPath,
+		tokenStoreLabel(m.cfg),
+		renderHealth(m.health),
 		tokenView,
+		login,
 	)
 	card := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -208,20 +389,43 @@ func missing(v, fallback string) string {
 
 type templateItem template
 
+// Title puts the emoji's glyph before labels that don't start with one.
 func (t templateItem) Title() string {
-	return t.Label
+	label := renderEmoji(t.Label)
+	if glyph := renderEmoji(t.Emoji); glyph != t.Emoji && labelName(label) == label {
+		label = glyph + " " + label
+	}
+	if t.Key != "" {
+		return fmt.Sprintf("[%s] %s", t.Key, label)
+	}
+	return label
 }
 
 func (t templateItem) Description() string {
 	var parts []string
 	if t.Text != "" {
-		parts = append(parts, t.Text)
+		parts = append(parts, renderEmoji(t.Text))
 	}
 	if t.DurationInMinutes != nil {
 		parts = append(parts, fmt.Sprintf("%dm", *t.DurationInMinutes))
 	}
-	if t.UntilTime != "" {
-		parts = append(parts, fmt.Sprintf("until %s", t.UntilTime))
+	if until := template(t).effectiveUntilTime(time.Now()); until != "" {
+		parts = append(parts, fmt.Sprintf("until %s", until))
+	}
+	switch t.UntilMeeting {
+	case untilNextMeeting:
+		parts = append(parts, "until next meeting")
+	case untilMeetingEnd:
+		parts = append(parts, "until meeting ends")
+	}
+	if t.Presence != "" {
+		parts = append(parts, t.Presence)
+	}
+	if t.DND {
+		parts = append(parts, "dnd")
+	}
+	if len(t.Workspaces) > 0 {
+		parts = append(parts, "→ "+strings.Join(t.Workspaces, ", "))
 	}
 	return strings.Join(parts, " \a ")
 }
@@ -324,12 +528,22 @@ func renderCalSyncStatusView(s calSyncState, enabled bool) string {
 	return card
 }
 
-func newDurationList(width, height int) list.Model {
+func newDurationList(width, height int, withCalendar bool) list.Model {
 	items := []list.Item{
 		durationOption{Label: "Tage", Unit: durationDays},
 		durationOption{Label: "Stunden", Unit: durationHours},
 		durationOption{Label: "Minuten", Unit: durationMinutes},
-		durationOption{Label: "Bis naechste Woche Montag", Unit: durationNextMonday},
+		durationOption{Label: "Arbeitstage", Unit: durationWorkdays},
+		durationOption{Label: "Bis Feierabend heute", Unit: durationEndOfWorkday},
+		durationOption{Label: "Bis zum naechsten Arbeitsbeginn", Unit: durationNextWorkday},
+		durationOption{Label: "Bis naechste Woche (Arbeitsbeginn)", Unit: durationNextMonday},
+		durationOption{Label: "Freie Eingabe (90m, freitag, 3 arbeitstage ...)", Unit: durationCustom},
+	}
+	if withCalendar {
+		items = append(items,
+			durationOption{Label: "Bis zum naechsten Meeting", Unit: durationNextMeeting},
+			durationOption{Label: "Bis Ende des laufenden Meetings", Unit: durationMeetingEnd},
+		)
 	}
 	delegate := newTemplateDelegate()
 	ls := list.New(items, delegate, width, height)
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// workSpan is one day's working hours in minutes after midnight.
+type workSpan struct {
+	start, end int
+}
+
+// defaultWorkingHours applies when config.json has no workingHours.
+var defaultWorkingHours = map[time.Weekday]workSpan{
+	time.Monday:    {9 * 60, 17 * 60},
+	time.Tuesday:   {9 * 60, 17 * 60},
+	time.Wednesday: {9 * 60, 17 * 60},
+	time.Thursday:  {9 * 60, 17 * 60},
+	time.Friday:    {9 * 60, 17 * 60},
+}
+
+// Like holidays, working hours are read from list delegates and commands.
+var (
+	workHoursMu sync.RWMutex
+	workHours   = defaultWorkingHours
+)
+
+// configureWorkingHours replaces the working hours from config.json, e.g.
+// {"mon": "08:00-16:30", "fri": "08:00-12:00"}. Weekdays that are missing or
+// set to "off" are days off.
+func configureWorkingHours(spec map[string]string) error {
+	hours := defaultWorkingHours
+	var problems []string
+	if len(spec) > 0 {
+		hours = map[time.Weekday]workSpan{}
+		keys := make([]string, 0, len(spec))
+		for k := range spec {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			wd, ok := weekdayKeys[strings.ToLower(k)]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("unknown weekday %q", k))
+				continue
+			}
+			v := strings.TrimSpace(spec[k])
+			if v == "" || strings.EqualFold(v, "off") || strings.EqualFold(v, "frei") {
+				continue
+			}
+			span, err := parseWorkSpan(v)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", k, err))
+				continue
+			}
+			hours[wd] = span
+		}
+	}
+	workHoursMu.Lock()
+	workHours = hours
+	workHoursMu.Unlock()
+	if len(problems) > 0 {
+		return fmt.Errorf("workingHours: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func parseWorkSpan(v string) (workSpan, error) {
+	a, b, ok := strings.Cut(v, "-")
+	if !ok {
+		return workSpan{}, fmt.Errorf("%q: expected HH:MM-HH:MM", v)
+	}
+	start, err1 := time.Parse("15:04", strings.TrimSpace(a))
+	end, err2 := time.Parse("15:04", strings.TrimSpace(b))
+	if err1 != nil || err2 != nil {
+		return workSpan{}, fmt.Errorf("%q: expected HH:MM-HH:MM", v)
+	}
+	span := workSpan{start.Hour()*60 + start.Minute(), end.Hour()*60 + end.Minute()}
+	if span.end <= span.start {
+		return workSpan{}, fmt.Errorf("%q: end must be after start", v)
+	}
+	return span, nil
+}
+
+// workSpanFor returns the working hours of day; ok is false on days off and
+// holidays.
+func workSpanFor(day time.Time) (workSpan, bool) {
+	workHoursMu.RLock()
+	span, ok := workHours[day.Weekday()]
+	workHoursMu.RUnlock()
+	if !ok || isHoliday(day) {
+		return workSpan{}, false
+	}
+	return span, true
+}
+
+// isWorkday reports whether day has working hours and is not a holiday.
+func isWorkday(day time.Time) bool {
+	_, ok := workSpanFor(day)
+	return ok
+}
+
+// atMinute returns the wall-clock time minute minutes after midnight on day,
+// also on days when the clocks change.
+func atMinute(day time.Time, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, day.Location())
+}
+
+// nextWorkdayStart returns when work starts next: later today if today is a
+// workday that hasn't started yet, otherwise on the next workday.
+func nextWorkdayStart(now time.Time) time.Time {
+	day := startOfDay(now)
+	if span, ok := workSpanFor(day); ok && now.Before(atMinute(day, span.start)) {
+		return atMinute(day, span.start)
+	}
+	return workdayStartAfter(day)
+}
+
+// workdayStartAfter returns the start of the first workday after day.
+func workdayStartAfter(day time.Time) time.Time {
+	day = startOfDay(day)
+	for i := 0; i < 366; i++ {
+		day = day.AddDate(0, 0, 1)
+		if span, ok := workSpanFor(day); ok {
+			return atMinute(day, span.start)
+		}
+	}
+	return day
+}
+
+// nextWeekStart returns the start of the first workday from next Monday on.
+func nextWeekStart(now time.Time) time.Time {
+	days := (int(time.Monday) - int(now.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return workdayStartAfter(startOfDay(now).AddDate(0, 0, days-1))
+}
+
+// endOfWorkday returns the end of today's working hours.
+func endOfWorkday(now time.Time) (time.Time, error) {
+	span, ok := workSpanFor(now)
+	if !ok {
+		return time.Time{}, fmt.Errorf("today is not a workday")
+	}
+	end := atMinute(now, span.end)
+	if !end.After(now) {
+		return time.Time{}, fmt.Errorf("today's workday ended at %s", end.Format("15:04"))
+	}
+	return end, nil
+}
+
+// afterWorkdays returns when work starts again after n workdays away. Today
+// counts as the first one if it is a workday that hasn't ended yet.
+func afterWorkdays(now time.Time, n int) (time.Time, error) {
+	if n <= 0 {
+		return time.Time{}, fmt.Errorf("number of workdays must be greater than 0")
+	}
+	day := startOfDay(now)
+	if span, ok := workSpanFor(day); ok && now.Before(atMinute(day, span.end)) {
+		n--
+	}
+	for ; n > 0; n-- {
+		day = startOfDay(workdayStartAfter(day))
+	}
+	return workdayStartAfter(day), nil
+}
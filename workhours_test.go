@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// useWorkCalendar sets the package-level holidays and working hours for one
+// test and restores the defaults afterwards.
+func useWorkCalendar(t *testing.T, region string, hours map[string]string) {
+	t.Helper()
+	if err := configureHolidays(config{HolidayRegion: region}); err != nil {
+		t.Fatal(err)
+	}
+	if err := configureWorkingHours(hours); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		configureHolidays(config{})
+		configureWorkingHours(nil)
+	})
+}
+
+func TestNextWorkdayStart(t *testing.T) {
+	loc := mustLoadLocation(t, "Europe/Berlin")
+	at := func(month time.Month, day, h, min int) time.Time {
+		return time.Date(2026, month, day, h, min, 0, 0, loc)
+	}
+	useWorkCalendar(t, "BY", nil)
+	tests := []struct {
+		now, want time.Time
+	}{
+		{at(3, 25, 8, 0), at(3, 25, 9, 0)},
+		{at(3, 25, 10, 0), at(3, 26, 9, 0)},
+		{at(3, 27, 18, 0), at(3, 30, 9, 0)},
+		// Karfreitag and Ostermontag are skipped.
+		{at(4, 2, 18, 0), at(4, 7, 9, 0)},
+	}
+	for _, tt := range tests {
+		if got := nextWorkdayStart(tt.now); !got.Equal(tt.want) {
+			t.Errorf("nextWorkdayStart(%s) = %s, want %s", tt.now, got, tt.want)
+		}
+	}
+
+	// Work starting on the day the clocks go forward keeps its wall time.
+	useWorkCalendar(t, "", map[string]string{"sun": "08:00-12:00"})
+	want := at(3, 29, 8, 0)
+	if got := nextWorkdayStart(at(3, 28, 12, 0)); !got.Equal(want) {
+		t.Errorf("on the DST day work starts at %s, want %s", got, want)
+	}
+}
+
+func TestAfterWorkdays(t *testing.T) {
+	loc := mustLoadLocation(t, "Europe/Berlin")
+	at := func(month time.Month, day, h, min int) time.Time {
+		return time.Date(2026, month, day, h, min, 0, 0, loc)
+	}
+	useWorkCalendar(t, "BY", nil)
+	tests := []struct {
+		now  time.Time
+		n    int
+		want time.Time
+	}{
+		// Today counts while it hasn't ended.
+		{at(3, 25, 10, 0), 1, at(3, 26, 9, 0)},
+		{at(3, 25, 8, 0), 2, at(3, 27, 9, 0)},
+		{at(3, 25, 18, 0), 1, at(3, 27, 9, 0)},
+		{at(3, 27, 10, 0), 1, at(3, 30, 9, 0)},
+		{at(4, 2, 10, 0), 2, at(4, 8, 9, 0)},
+		// Fronleichnam is a holiday in Bavaria.
+		{at(6, 3, 10, 0), 1, at(6, 5, 9, 0)},
+	}
+	for _, tt := range tests {
+		got, err := afterWorkdays(tt.now, tt.n)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("afterWorkdays(%s, %d) = %s, %v; want %s", tt.now, tt.n, got, err, tt.want)
+		}
+	}
+	if _, err := afterWorkdays(at(3, 25, 10, 0), 0); err == nil {
+		t.Error("afterWorkdays accepted 0 workdays")
+	}
+}
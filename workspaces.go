@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/slack-go/slack"
+)
+
+// workspace is a configured Slack workspace with its client. A config with
+// only slackToken has a single workspace with an empty name. Status writes
+// that can't reach Slack go to offlineQueue.
+type workspace struct {
+	name         string
+	token        string
+	client       *slack.Client
+	calSync      bool
+	offlineQueue string
+}
+
+// buildWorkspaces creates a client per configured workspace, the first one
+// being the primary workspace whose presence and DND the card shows.
+func buildWorkspaces(cfg config, offlineQueue string) ([]workspace, error) {
+	if len(cfg.Workspaces) == 0 {
+		if cfg.SlackToken == "" {
+			return nil, errNoToken
+		}
+		return []workspace{{
+			token:        cfg.SlackToken,
+			client:       slack.New(cfg.SlackToken, clientOptions(cfg)...),
+			calSync:      true,
+			offlineQueue: offlineQueue,
+		}}, nil
+	}
+	seen := map[string]bool{}
+	out := make([]workspace, 0, len(cfg.Workspaces))
+	for i, wc := range cfg.Workspaces {
+		name := strings.TrimSpace(wc.Name)
+		if name == "" {
+			return nil, fmt.Errorf("workspaces[%d]: name missing", i)
+		}
+		if seen[strings.ToLower(name)] {
+			return nil, fmt.Errorf("workspace %q configured twice", name)
+		}
+		seen[strings.ToLower(name)] = true
+		if wc.Token == "" {
+			return nil, fmt.Errorf("workspace %q: token missing", name)
+		}
+		out = append(out, workspace{
+			name:         name,
+			token:        wc.Token,
+			client:       slack.New(wc.Token, clientOptions(cfg)...),
+			calSync:      wc.CalSync == nil || *wc.CalSync,
+			offlineQueue: offlineQueue,
+		})
+	}
+	return out, nil
+}
+
+// label is the name shown in messages and on the status card.
+func (ws workspace) label() string {
+	return missing(ws.name, "Slack")
+}
+
+// wrap prefixes err with the workspace name when there is more than one.
+func (ws workspace) wrap(err error) error {
+	if err == nil || ws.name == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", ws.name, err)
+}
+
+// statePath keeps the primary workspace's cal-sync snapshot in base and the
+// others next to it, e.g. calendar-sync-state-partner.json.
+func (ws workspace) statePath(base string, primary bool) string {
+	if primary || ws.name == "" {
+		return base
+	}
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-" + strings.ToLower(ws.name) + ext
+}
+
+// targetsFor returns the workspaces a template addresses; none means all.
+func (m model) targetsFor(names []string) ([]workspace, error) {
+	if len(names) == 0 {
+		return m.workspaces, nil
+	}
+	var out []workspace
+	for _, n := range names {
+		found := false
+		for _, ws := range m.workspaces {
+			if strings.EqualFold(ws.name, n) {
+				out = append(out, ws)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown workspace %q", n)
+		}
+	}
+	return out, nil
+}
+
+// calSyncTargets are the workspaces that get the meeting status.
+func (m model) calSyncTargets() []workspace {
+	var out []workspace
+	for _, ws := range m.workspaces {
+		if ws.calSync {
+			out = append(out, ws)
+		}
+	}
+	return out
+}
+
+func workspaceNames(targets []workspace) []string {
+	names := make([]string, len(targets))
+	for i, ws := range targets {
+		names[i] = ws.name
+	}
+	return names
+}
+
+func (m model) isPrimary(ws workspace) bool {
+	return len(m.workspaces) > 0 && m.workspaces[0].name == ws.name
+}
+
+// parseWorkspaceList reads the comma-separated workspaces field of the forms.
+func parseWorkspaceList(v string) []string {
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// ── Broadcast ────────────────────────────────────────────────────────────────
+
+// Actions reported by workspaceResultsMsg.
+const (
+	actionStatus   = "status"
+	actionPresence = "presence"
+	actionDND      = "dnd"
+)
+
+// eachWorkspace runs fn for all targets in parallel, each with its own
+// timeout that leaves room for retries, and returns one result per target in
+// order.
+func eachWorkspace(targets []workspace, fn func(ctx context.Context, ws workspace) error) []workspaceResult {
+	results := make([]workspaceResult, len(targets))
+	var wg sync.WaitGroup
+	for i, ws := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), slackCallTimeout)
+			defer cancel()
+			results[i] = workspaceResult{Workspace: ws.name, Err: fn(ctx, ws)}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// joinResults combines the failures of a broadcast into one error.
+func joinResults(targets []workspace, results []workspaceResult) error {
+	var errs []error
+	for i, r := range results {
+		if r.Err != nil {
+			errs = append(errs, targets[i].wrap(r.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func anySucceeded(results []workspaceResult) bool {
+	for _, r := range results {
+		if r.Err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// currentStatuses reads the status of every target in parallel, keyed by
+// workspace name. It fails if any of them can't be read.
+func currentStatuses(targets []workspace) (map[string]statusInfo, error) {
+	if len(targets) == 0 {
+		return nil, errors.New("no Slack client configured")
+	}
+	var mu sync.Mutex
+	current := make(map[string]statusInfo, len(targets))
+	results := eachWorkspace(targets, func(ctx context.Context, ws workspace) error {
+		profile, err := ws.client.GetUserProfileContext(ctx, &slack.GetUserProfileParameters{})
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		current[ws.name] = statusInfo{
+			Workspace:      ws.name,
+			Text:           profile.StatusText,
+			Emoji:          profile.StatusEmoji,
+			ExpirationUnix: int64(profile.StatusExpiration),
+		}
+		return nil
+	})
+	if err := joinResults(targets, results); err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+func broadcastCmd(targets []workspace, action string, fn func(ctx context.Context, ws workspace) error) tea.Cmd {
+	return func() tea.Msg {
+		if len(targets) == 0 {
+			return errMsg{errors.New("no Slack client configured")}
+		}
+		return workspaceResultsMsg{Action: action, Results: eachWorkspace(targets, fn)}
+	}
+}
+
+// handleWorkspaceResults reports a broadcast per workspace: failures are
+// kept on the status card next to that workspace, the others refresh.
+func (m model) handleWorkspaceResults(msg workspaceResultsMsg) (model, tea.Cmd) {
+	var ok []string
+	var errs []error
+	var cmds []tea.Cmd
+	queued := false
+	for _, r := range msg.Results {
+		queued = queued || errors.Is(r.Err, errQueued)
+		ws, found := m.workspaceNamed(r.Workspace)
+		if !found {
+			continue
+		}
+		if r.Err != nil {
+			err := fmt.Errorf("%s: %w", msg.Action, r.Err)
+			m.workspaceErrs[ws.name] = err
+			errs = append(errs, ws.wrap(err))
+			continue
+		}
+		delete(m.workspaceErrs, ws.name)
+		ok = append(ok, ws.label())
+		switch msg.Action {
+		case actionStatus:
+			m.markOwnWrite(ws.name)
+			cmds = append(cmds, fetchStatusCmd(ws))
+		case actionPresence:
+			if m.isPrimary(ws) {
+				cmds = append(cmds, fetchPresenceCmd(ws.client))
+			}
+		case actionDND:
+			if m.isPrimary(ws) {
+				cmds = append(cmds, fetchDNDCmd(ws.client))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		m.err = errors.Join(errs...)
+	}
+	if queued {
+		cmds = append(cmds, loadOfflineQueueCmd(m.offlineQueuePath))
+	}
+	if msg.Action == actionStatus && len(ok) > 0 {
+		m.message = "Status updated"
+		if len(m.workspaces) > 1 {
+			m.message += " in " + strings.Join(ok, ", ")
+		}
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m model) workspaceNamed(name string) (workspace, bool) {
+	return findWorkspace(m.workspaces, name)
+}
+
+// fetchStatusesCmd refreshes the status of every workspace.
+func (m model) fetchStatusesCmd() tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(m.workspaces))
+	for _, ws := range m.workspaces {
+		cmds = append(cmds, fetchStatusCmd(ws))
+	}
+	return tea.Batch(cmds...)
+}
+
+// workspaceLines lists the status of each workspace for the status card;
+// a single workspace has none, its status is the card itself.
+func (m model) workspaceLines() []string {
+	if len(m.workspaces) < 2 {
+		return nil
+	}
+	lines := make([]string, 0, len(m.workspaces))
+	for _, ws := range m.workspaces {
+		line := ws.label() + ": "
+		if info, ok := m.statuses[ws.name]; ok {
+			line += renderEmoji(strings.TrimSpace(info.Emoji + " " + missing(info.Text, "-")))
+			if info.Expiration != "" {
+				line += " (until " + info.Expiration + ")"
+			}
+		} else {
+			line += "…"
+		}
+		if err := m.workspaceErrs[ws.name]; err != nil {
+			line += " ✗ " + err.Error()
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}